@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/protoc-gen/protoc-gen-openapiv3/openapiv3"
+)
+
+// listFlag collects a flag given several times, values may also be separated by commas
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// runGenerate runs the generate subcommand, it generates the documents from a
+// descriptor set without running protoc, e.g.
+// protoc-gen-openapiv3 generate --descriptor-set image.bin --files trip.proto --out api.yaml
+func runGenerate(args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: protoc-gen-openapiv3 generate --descriptor-set FILE [flags]")
+		fmt.Fprintln(flags.Output(), "\nGenerates OpenAPI documents from a protoc --descriptor_set_out or buf build image.")
+		fmt.Fprintln(flags.Output(), "\nFlags:")
+		flags.PrintDefaults()
+	}
+	var (
+		files   listFlag
+		options listFlag
+	)
+	descriptorSet := flags.String("descriptor-set", "", "descriptor set or buf image `file`, read as JSON when it ends with .json")
+	flags.Var(&files, "files", "proto files to generate, e.g. trip/v1/trip.proto (default the files of the set that aren't imports)")
+	flags.Var(&options, "opt", "plugin options as key=value, e.g. output_strategy=per_file")
+	out := flags.String("out", "", "file the single document is written to, - for stdout, the format follows the extension")
+	outDir := flags.String("out-dir", ".", "directory the documents are written to when --out isn't given")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if *descriptorSet == "" {
+		flags.Usage()
+		return fmt.Errorf("--descriptor-set is required")
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", flags.Args())
+	}
+
+	set, err := readDescriptorSet(*descriptorSet)
+	if err != nil {
+		return err
+	}
+	opts, err := openapiv3.ParseOptions(options.String())
+	if err != nil {
+		return err
+	}
+	if *out != "" {
+		opts.OutputFormat = openapiv3.OutputFormatYAML
+		if path.Ext(*out) == ".json" {
+			opts.OutputFormat = openapiv3.OutputFormatJSON
+		}
+	}
+
+	generated, err := openapiv3.GenerateFiles(set, files, opts)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		for _, file := range generated {
+			name := filepath.Join(*outDir, filepath.FromSlash(file.Name))
+			if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(name, file.Content, 0o644); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(generated) != 1 {
+		return fmt.Errorf("--out needs a single document but %d were generated, use --out-dir instead", len(generated))
+	}
+	if *out == "-" {
+		_, err := os.Stdout.Write(generated[0].Content)
+		return err
+	}
+	return os.WriteFile(*out, generated[0].Content, 0o644)
+}
+
+// readDescriptorSet reads a FileDescriptorSet, buf images are read as well
+// since they're wire compatible, their extra fields are ignored
+func readDescriptorSet(name string) (*descriptorpb.FileDescriptorSet, error) {
+	var b []byte
+	var err error
+	if name == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	if path.Ext(name) == ".json" {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, set)
+	} else {
+		err = proto.Unmarshal(b, set)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading descriptor set %s: %w", name, err)
+	}
+	return set, nil
+}
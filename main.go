@@ -1,19 +1,22 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"google.golang.org/protobuf/compiler/protogen"
 
 	"github.com/protoc-gen/protoc-gen-openapiv3/openapiv3"
 )
 
 func main() {
-	protogen.Options{}.Run(func(gen *protogen.Plugin) error {
-		for _, f := range gen.Files {
-			if !f.Generate {
-				continue
-			}
-			openapiv3.GenerateFile(gen, f)
+	// protoc runs the plugin without arguments, the generate subcommand reads descriptor sets instead
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		if err := runGenerate(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "protoc-gen-openapiv3: %v\n", err)
+			os.Exit(1)
 		}
-		return nil
-	})
+		return
+	}
+	protogen.Options{}.Run(openapiv3.Generate)
 }
@@ -0,0 +1,95 @@
+package openapiv3
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+)
+
+// getComments returns the leading and trailing comments of a declaration as a
+// description, detached comments are ignored and so are the lines starting with
+// one of the ignore_comment_prefixes, e.g. buf:lint:ignore
+func getComments(opts *Options, comments protogen.CommentSet) string {
+	var paragraphs []string
+	for _, comment := range []protogen.Comments{comments.Leading, comments.Trailing} {
+		var lines []string
+		for _, line := range strings.Split(string(comment), "\n") {
+			// protoc keeps the space following the comment marker
+			line = strings.TrimRight(strings.TrimPrefix(line, " "), " \t")
+			if isIgnoredComment(opts, line) {
+				continue
+			}
+			lines = append(lines, line)
+		}
+		if text := strings.TrimSpace(strings.Join(lines, "\n")); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// isIgnoredComment reports whether the comment line is left out of descriptions
+func isIgnoredComment(opts *Options, line string) bool {
+	line = strings.TrimSpace(line)
+	for _, prefix := range opts.IgnoreCommentPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// getSummaryAndDescription splits the comments of a method into the summary,
+// which is the first line, and the description made of the following lines. The
+// summary option takes precedence, the whole comment is then the description.
+func getSummaryAndDescription(opts *Options, method *protogen.Method) (string, string) {
+	text := getComments(opts, method.Comments)
+	methodOpts := proto.GetExtension(method.Desc.Options(), E_Method).(*Method)
+	if summary := methodOpts.GetSummary(); summary != "" {
+		return summary, text
+	}
+	summary, description, _ := strings.Cut(text, "\n")
+	return summary, strings.TrimSpace(description)
+}
+
+// getFieldTitle returns the title of a field given by the summary of the field option
+func getFieldTitle(field *protogen.Field) string {
+	fieldOpts := proto.GetExtension(field.Desc.Options(), E_Field).(*Field)
+	return fieldOpts.GetSummary()
+}
+
+// getFieldDescription returns the description of a field, the description of
+// the field option takes precedence over the comments. Enum fields fall back to
+// the comments of the enum and list the values that are commented, e.g.
+// - `ACTIVE`: the trip is in progress
+func getFieldDescription(opts *Options, field *protogen.Field) string {
+	fieldOpts := proto.GetExtension(field.Desc.Options(), E_Field).(*Field)
+	if description := fieldOpts.GetDescription(); description != "" {
+		return description
+	}
+
+	description := getComments(opts, field.Comments)
+	if field.Enum == nil {
+		return description
+	}
+	if description == "" {
+		description = getComments(opts, field.Enum.Comments)
+	}
+
+	var values []string
+	for _, value := range field.Enum.Values {
+		if comment := getComments(opts, value.Comments); comment != "" {
+			comment = strings.Join(strings.Fields(comment), " ")
+			values = append(values, fmt.Sprintf("- `%s`: %s", value.Desc.Name(), comment))
+		}
+	}
+	if len(values) == 0 {
+		return description
+	}
+	if description != "" {
+		description += "\n\n"
+	}
+	return description + strings.Join(values, "\n")
+}
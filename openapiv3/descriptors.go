@@ -0,0 +1,226 @@
+package openapiv3
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// GenerateDocuments generates the documents of the files of a descriptor set
+// without running protoc, e.g. a set written by protoc --include_imports
+// --descriptor_set_out or buf build. The set must contain the dependencies of
+// the files, which are named by their import path such as trip/v1/trip.proto.
+// No files generate the files of the set that aren't imports, see
+// getDefaultFiles, and nil options are the defaults.
+func GenerateDocuments(set *descriptorpb.FileDescriptorSet, files []string, opts *Options) ([]*GeneratedDocument, error) {
+	gen, opts, err := newPlugin(set, files, opts)
+	if err != nil {
+		return nil, err
+	}
+	return generateDocuments(gen, opts)
+}
+
+// GenerateFiles is GenerateDocuments returning the serialized documents, the
+// same files the protoc plugin writes with these options
+func GenerateFiles(set *descriptorpb.FileDescriptorSet, files []string, opts *Options) ([]*File, error) {
+	gen, opts, err := newPlugin(set, files, opts)
+	if err != nil {
+		return nil, err
+	}
+	return generateFiles(gen, opts)
+}
+
+// DescriptorSetFromRegistry returns the files of the registry as a descriptor
+// set, e.g. protoregistry.GlobalFiles for the protos compiled into a program
+func DescriptorSetFromRegistry(files *protoregistry.Files) *descriptorpb.FileDescriptorSet {
+	set := &descriptorpb.FileDescriptorSet{}
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(file))
+		return true
+	})
+	return set
+}
+
+// newPlugin builds the code generator request protoc would send for the files
+func newPlugin(set *descriptorpb.FileDescriptorSet, files []string, opts *Options) (*protogen.Plugin, *Options, error) {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	// The set goes through the wire format like a protoc request, so custom
+	// options are decoded as the generated Go types whatever produced the set
+	b, err := proto.Marshal(set)
+	if err != nil {
+		return nil, nil, err
+	}
+	set = &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(b, set); err != nil {
+		return nil, nil, err
+	}
+
+	protoFiles, err := sortByDependencies(set.GetFile())
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(files) == 0 {
+		files = getDefaultFiles(protoFiles)
+	}
+
+	// protogen needs a Go import path for every file even though no Go code is
+	// generated, files without go_package get one made of their name
+	var params []string
+	if opts.Paths != "" {
+		params = append(params, "paths="+opts.Paths)
+	}
+	for _, file := range protoFiles {
+		if file.GetOptions().GetGoPackage() == "" {
+			params = append(params, fmt.Sprintf("M%s=%s", file.GetName(), getGoImportPath(file.GetName())))
+		}
+	}
+
+	gen, err := protogen.Options{}.New(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: files,
+		Parameter:      proto.String(strings.Join(params, ",")),
+		ProtoFile:      protoFiles,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return gen, opts, nil
+}
+
+// bufImageFileExtension is the field of FileDescriptorProto holding the
+// ImageFileExtension of buf images, its is_import field marks the imports.
+// buf images:
+// https://buf.build/docs/reference/images/
+const (
+	bufImageFileExtension protowire.Number = 8042
+	bufImageIsImport      protowire.Number = 1
+)
+
+// getDefaultFiles returns the files generated when none are given. buf images
+// mark their imports, in other sets the files imported by another file of the
+// set are left out, e.g. google/api/annotations.proto.
+func getDefaultFiles(protoFiles []*descriptorpb.FileDescriptorProto) []string {
+	var files []string
+	var isImage bool
+	for _, file := range protoFiles {
+		isImport, ok := getBufIsImport(file)
+		isImage = isImage || ok
+		if !isImport {
+			files = append(files, file.GetName())
+		}
+	}
+	if isImage {
+		return files
+	}
+
+	imported := make(map[string]bool)
+	for _, file := range protoFiles {
+		for _, dependency := range file.GetDependency() {
+			imported[dependency] = true
+		}
+	}
+	files = files[:0]
+	for _, file := range protoFiles {
+		if !imported[file.GetName()] {
+			files = append(files, file.GetName())
+		}
+	}
+	return files
+}
+
+// getBufIsImport returns the is_import field of the buf image extension of a
+// file, ok is false when the file has no extension, i.e. it's not from an image
+func getBufIsImport(file *descriptorpb.FileDescriptorProto) (isImport, ok bool) {
+	b := file.ProtoReflect().GetUnknown()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return false, false
+		}
+		b = b[n:]
+		if num != bufImageFileExtension || typ != protowire.BytesType {
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return false, false
+			}
+			b = b[n:]
+			continue
+		}
+		extension, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return false, false
+		}
+		b, ok = b[n:], true
+		for len(extension) > 0 {
+			num, typ, n := protowire.ConsumeTag(extension)
+			if n < 0 {
+				break
+			}
+			extension = extension[n:]
+			if num == bufImageIsImport && typ == protowire.VarintType {
+				v, n := protowire.ConsumeVarint(extension)
+				if n < 0 {
+					break
+				}
+				isImport = protowire.DecodeBool(v)
+			}
+			if n = protowire.ConsumeFieldValue(num, typ, extension); n < 0 {
+				break
+			}
+			extension = extension[n:]
+		}
+	}
+	return isImport, ok
+}
+
+// sortByDependencies orders the files so that dependencies come before the
+// files importing them, like protoc does in code generator requests
+func sortByDependencies(files []*descriptorpb.FileDescriptorProto) ([]*descriptorpb.FileDescriptorProto, error) {
+	byName := make(map[string]*descriptorpb.FileDescriptorProto, len(files))
+	for _, file := range files {
+		if _, ok := byName[file.GetName()]; ok {
+			return nil, fmt.Errorf("duplicate file %q in descriptor set", file.GetName())
+		}
+		byName[file.GetName()] = file
+	}
+
+	sorted := make([]*descriptorpb.FileDescriptorProto, 0, len(files))
+	visited := make(map[string]bool, len(files))
+	var visit func(file *descriptorpb.FileDescriptorProto)
+	visit = func(file *descriptorpb.FileDescriptorProto) {
+		if visited[file.GetName()] {
+			return
+		}
+		visited[file.GetName()] = true
+		// Missing dependencies are reported by protogen
+		for _, dependency := range file.GetDependency() {
+			if dep, ok := byName[dependency]; ok {
+				visit(dep)
+			}
+		}
+		sorted = append(sorted, file)
+	}
+	for _, file := range files {
+		visit(file)
+	}
+	return sorted, nil
+}
+
+// getGoImportPath returns a Go import path for a file without go_package,
+// protogen wants import paths with at least a slash, e.g. ./trip/v1/trip
+func getGoImportPath(filename string) string {
+	return "./" + strings.TrimSuffix(filename, path.Ext(filename))
+}
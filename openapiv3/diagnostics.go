@@ -0,0 +1,127 @@
+package openapiv3
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Severity is the severity of a diagnostic
+type Severity int
+
+const (
+	// SeverityWarning is a problem the document is still generated with
+	SeverityWarning Severity = iota
+	// SeverityError is a problem that fails the generation
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Diagnostic is a problem found while generating a document, it's located in
+// the proto source when it comes from a descriptor
+type Diagnostic struct {
+	Severity Severity
+	// File is the proto file, it's empty when the problem has no location
+	File string
+	// Line and Column start at 1, they're 0 when the file has no source info
+	Line    int
+	Column  int
+	Message string
+}
+
+// String formats the diagnostic like a compiler, e.g.
+// example/trip.proto:12:3: warning: unknown path variable "trip_id"
+func (d Diagnostic) String() string {
+	var location string
+	switch {
+	case d.File != "" && d.Line > 0:
+		location = fmt.Sprintf("%s:%d:%d: ", d.File, d.Line, d.Column)
+	case d.File != "":
+		location = d.File + ": "
+	}
+	return fmt.Sprintf("%s%s: %s", location, d.Severity, d.Message)
+}
+
+// Diagnostics is the error returned when a generation fails, it holds every
+// error found, and the warnings when they're treated as errors
+type Diagnostics []Diagnostic
+
+func (d Diagnostics) Error() string {
+	lines := make([]string, 0, len(d))
+	for _, diagnostic := range d {
+		lines = append(lines, diagnostic.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// diagnostics collects the diagnostics of a generation, the same problem is
+// only reported once even when a message is used by several documents
+type diagnostics struct {
+	list Diagnostics
+	seen map[Diagnostic]bool
+}
+
+// warnf reports a warning located at the descriptor, which may be nil
+func (d *diagnostics) warnf(desc protoreflect.Descriptor, format string, args ...any) {
+	d.add(SeverityWarning, desc, fmt.Sprintf(format, args...))
+}
+
+// errorf reports an error located at the descriptor, which may be nil
+func (d *diagnostics) errorf(desc protoreflect.Descriptor, format string, args ...any) {
+	d.add(SeverityError, desc, fmt.Sprintf(format, args...))
+}
+
+func (d *diagnostics) add(severity Severity, desc protoreflect.Descriptor, message string) {
+	diagnostic := Diagnostic{Severity: severity, Message: message}
+	if desc != nil && desc.ParentFile() != nil {
+		file := desc.ParentFile()
+		diagnostic.File = file.Path()
+		// Source info is only present for the files protoc was asked to generate
+		if loc := file.SourceLocations().ByDescriptor(desc); loc.Path != nil {
+			diagnostic.Line = loc.StartLine + 1
+			diagnostic.Column = loc.StartColumn + 1
+		}
+	}
+
+	if d.seen == nil {
+		d.seen = make(map[Diagnostic]bool)
+	}
+	if d.seen[diagnostic] {
+		return
+	}
+	d.seen[diagnostic] = true
+	d.list = append(d.list, diagnostic)
+}
+
+// result reports the warnings and returns the errors as Diagnostics, warnings
+// are errors with the warnings_as_errors option
+func (d *diagnostics) result(opts *Options) error {
+	var errs Diagnostics
+	for _, diagnostic := range d.list {
+		if diagnostic.Severity == SeverityWarning && opts.WarningsAsErrors {
+			diagnostic.Severity = SeverityError
+		}
+		if diagnostic.Severity == SeverityError {
+			errs = append(errs, diagnostic)
+			continue
+		}
+		if opts.OnWarning != nil {
+			opts.OnWarning(diagnostic)
+		} else {
+			// protoc passes the stderr of plugins through, stdout is the response
+			fmt.Fprintf(os.Stderr, "protoc-gen-openapiv3: %s\n", diagnostic)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
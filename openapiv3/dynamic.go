@@ -0,0 +1,180 @@
+package openapiv3
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// getDynamicExtension returns the value of a message extension of the options
+// without linking the Go package of the extension, e.g. validatex.rules. The
+// extension is decoded with its descriptor found in the imports of the file,
+// the result is nil when the file doesn't import it or the option isn't set.
+func getDynamicExtension(file protoreflect.FileDescriptor, options proto.Message, name protoreflect.FullName) protoreflect.Message {
+	extension := findExtension(file, name, make(map[string]bool))
+	if extension == nil || extension.Message() == nil {
+		return nil
+	}
+
+	// Options hold the extension as an unknown field, or as a known one when its
+	// Go package is linked anyway, marshalling handles both the same way
+	b, err := proto.Marshal(options)
+	if err != nil {
+		return nil
+	}
+	var value []byte
+	var found bool
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil
+		}
+		b = b[n:]
+		if num == extension.Number() && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil
+			}
+			// Repeated occurrences of a message field are merged
+			value, found = append(value, v...), true
+			b = b[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return nil
+		}
+		b = b[n:]
+	}
+	if !found {
+		return nil
+	}
+
+	message := dynamicpb.NewMessage(extension.Message())
+	if err := proto.Unmarshal(value, message); err != nil {
+		return nil
+	}
+	return message
+}
+
+// findExtension looks up an extension declared in the file or its imports
+func findExtension(file protoreflect.FileDescriptor, name protoreflect.FullName, visited map[string]bool) protoreflect.ExtensionDescriptor {
+	if visited[file.Path()] {
+		return nil
+	}
+	visited[file.Path()] = true
+
+	if file.Package() == name.Parent() {
+		if extension := file.Extensions().ByName(name.Name()); extension != nil {
+			return extension
+		}
+	}
+	imports := file.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		if extension := findExtension(imports.Get(i).FileDescriptor, name, visited); extension != nil {
+			return extension
+		}
+	}
+	return nil
+}
+
+// getDynamicMessage returns the message field of a dynamic message by name, nil
+// when the field is unknown or not set
+func getDynamicMessage(message protoreflect.Message, name protoreflect.Name) protoreflect.Message {
+	value, ok := getDynamicValue(message, name)
+	if !ok {
+		return nil
+	}
+	if m, ok := value.Interface().(protoreflect.Message); ok {
+		return m
+	}
+	return nil
+}
+
+// getDynamicValue returns a field of a dynamic message by name, fields unknown
+// to the descriptor are reported as not set so that older rule versions work
+func getDynamicValue(message protoreflect.Message, name protoreflect.Name) (protoreflect.Value, bool) {
+	if message == nil {
+		return protoreflect.Value{}, false
+	}
+	field := message.Descriptor().Fields().ByName(name)
+	if field == nil || !message.Has(field) {
+		return protoreflect.Value{}, false
+	}
+	return message.Get(field), true
+}
+
+// getDynamicNumber returns a numeric field of a dynamic message as a float
+func getDynamicNumber(message protoreflect.Message, name protoreflect.Name) (float64, bool) {
+	value, ok := getDynamicValue(message, name)
+	if !ok {
+		return 0, false
+	}
+	return toFloat(value.Interface())
+}
+
+// getDynamicUint returns a length or count field of a dynamic message, nil when
+// it's not set or negative
+func getDynamicUint(message protoreflect.Message, name protoreflect.Name) *uint64 {
+	value, ok := getDynamicNumber(message, name)
+	if !ok || value < 0 {
+		return nil
+	}
+	return newUint(uint64(value))
+}
+
+// getDynamicList returns the values of a repeated scalar field of a dynamic
+// message, enum numbers are returned as int32
+func getDynamicList(message protoreflect.Message, name protoreflect.Name) []any {
+	value, ok := getDynamicValue(message, name)
+	if !ok {
+		return nil
+	}
+	list, ok := value.Interface().(protoreflect.List)
+	if !ok {
+		return nil
+	}
+	values := make([]any, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		v := list.Get(i).Interface()
+		if number, ok := v.(protoreflect.EnumNumber); ok {
+			v = int32(number)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+// toFloat converts a numeric proto value to a float
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// containsValue reports whether the values hold the value
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func newUint(v uint64) *uint64 {
+	return &v
+}
@@ -0,0 +1,224 @@
+package openapiv3
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/openapi"
+)
+
+// formatExamples are the default examples of the string formats set by validation rules
+var formatExamples = map[string]string{
+	"email":         "user@example.com",
+	"uuid":          "00000000-0000-0000-0000-000000000000",
+	"uri":           "https://example.com",
+	"uri-reference": "https://example.com",
+	"hostname":      "example.com",
+	"ipv4":          "192.0.2.1",
+	"ipv6":          "2001:db8::1",
+}
+
+// fitExample changes the default example of a field to meet the constraints
+// of its property, e.g. a minimum length or an email format, so that documents
+// pass their own validation. Examples set with the example option are kept as
+// is, ok is false when no example meeting the constraints is found and the
+// example is left out.
+func fitExample(field *protogen.Field, property *openapi.Schema, example any) (any, bool) {
+	if proto.GetExtension(field.Desc.Options(), E_Example).(*Example) != nil {
+		return example, true
+	}
+	return fitSchemaExample(property, example)
+}
+
+// fitSchemaExample changes an example to meet the constraints of the schema
+func fitSchemaExample(schema *openapi.Schema, example any) (any, bool) {
+	if schema == nil || example == nil {
+		return example, true
+	}
+	switch value := example.(type) {
+	case []any:
+		return fitListExample(schema, value)
+	case map[string]any:
+		return fitMapExample(schema, value)
+	case string:
+		return fitStringExample(schema, value)
+	case bool:
+		return value, true
+	}
+	if number, ok := toFloat(example); ok {
+		return fitNumberExample(schema, example, number)
+	}
+	if number, ok := example.(int); ok {
+		return fitNumberExample(schema, example, float64(number))
+	}
+	return example, true
+}
+
+// fitListExample fits the items and repeats the first one up to the minimum
+// number of items, unless they must be unique
+func fitListExample(schema *openapi.Schema, example []any) (any, bool) {
+	items := make([]any, 0, len(example))
+	for _, item := range example {
+		item, ok := fitSchemaExample(schema.Items, item)
+		if !ok {
+			return nil, false
+		}
+		items = append(items, item)
+	}
+	if schema.MinItems != nil && uint64(len(items)) < *schema.MinItems {
+		if len(items) == 0 || schema.UniqueItems {
+			return nil, false
+		}
+		for uint64(len(items)) < *schema.MinItems {
+			items = append(items, items[0])
+		}
+	}
+	if schema.MaxItems != nil && uint64(len(items)) > *schema.MaxItems {
+		items = items[:*schema.MaxItems]
+	}
+	return items, true
+}
+
+// fitMapExample fits the values of a map and drops entries above the maximum
+// number of properties, messages referenced by $ref are kept as is
+func fitMapExample(schema *openapi.Schema, example map[string]any) (any, bool) {
+	if schema.AdditionalProperties == nil || schema.AdditionalProperties.Schema == nil {
+		return example, true
+	}
+	keys := make([]string, 0, len(example))
+	for key := range example {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if schema.MaxProperties != nil && uint64(len(keys)) > *schema.MaxProperties {
+		keys = keys[:*schema.MaxProperties]
+	}
+	if schema.MinProperties != nil && uint64(len(keys)) < *schema.MinProperties {
+		return nil, false
+	}
+	values := make(map[string]any, len(keys))
+	for _, key := range keys {
+		value, ok := fitSchemaExample(schema.AdditionalProperties.Schema, example[key])
+		if !ok {
+			return nil, false
+		}
+		values[key] = value
+	}
+	return values, true
+}
+
+// fitStringExample picks the first allowed value, an example of the format or
+// pads the string to the minimum length
+func fitStringExample(schema *openapi.Schema, example string) (any, bool) {
+	if len(schema.Enum) > 0 {
+		if containsValue(schema.Enum, example) {
+			return example, true
+		}
+		value, ok := schema.Enum[0].(string)
+		return value, ok
+	}
+	if formatExample, ok := formatExamples[schema.Format]; ok && example == "" {
+		example = formatExample
+	}
+
+	// Bytes are base64 encoded, A is a valid base64 character
+	padding := "a"
+	if schema.Format == "byte" {
+		padding = "A"
+	}
+	if schema.MinLength != nil && uint64(utf8.RuneCountInString(example)) < *schema.MinLength {
+		example += strings.Repeat(padding, int(*schema.MinLength)-utf8.RuneCountInString(example))
+	}
+	if schema.Not != nil && containsValue(schema.Not.Enum, example) {
+		for i := 0; i <= len(schema.Not.Enum) && containsValue(schema.Not.Enum, example); i++ {
+			example += padding
+		}
+	}
+
+	if schema.MaxLength != nil && uint64(utf8.RuneCountInString(example)) > *schema.MaxLength {
+		return nil, false
+	}
+	if schema.Pattern != "" {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err != nil || !pattern.MatchString(example) {
+			return nil, false
+		}
+	}
+	return example, true
+}
+
+// fitNumberExample picks the first allowed value or moves the number within its
+// bounds, the example keeps its Go type
+func fitNumberExample(schema *openapi.Schema, example any, number float64) (any, bool) {
+	if len(schema.Enum) > 0 {
+		for _, value := range schema.Enum {
+			if v, ok := toFloat(value); ok && v == number {
+				return example, true
+			}
+		}
+		return schema.Enum[0], true
+	}
+
+	integer := len(schema.Type) > 0 && schema.Type[0] == "integer"
+	if schema.Minimum != nil && (number < *schema.Minimum || (schema.ExclusiveMinimum && number == *schema.Minimum)) {
+		number = *schema.Minimum
+		if integer {
+			number = math.Ceil(number)
+		}
+		if schema.ExclusiveMinimum && number == *schema.Minimum {
+			number++
+		}
+	}
+	if schema.Maximum != nil && (number > *schema.Maximum || (schema.ExclusiveMaximum && number == *schema.Maximum)) {
+		number = *schema.Maximum
+		if integer {
+			number = math.Floor(number)
+		}
+		if schema.ExclusiveMaximum && number == *schema.Maximum {
+			number--
+		}
+	}
+	if schema.Not != nil {
+		for i := 0; i <= len(schema.Not.Enum) && containsNumber(schema.Not.Enum, number); i++ {
+			number++
+		}
+	}
+
+	if !isWithinBounds(schema, number) || (schema.Not != nil && containsNumber(schema.Not.Enum, number)) {
+		return nil, false
+	}
+	switch example.(type) {
+	case int:
+		return int(number), true
+	case float32:
+		return float32(number), true
+	}
+	return number, true
+}
+
+// isWithinBounds reports whether the number meets the minimum and maximum of the schema
+func isWithinBounds(schema *openapi.Schema, number float64) bool {
+	if schema.Minimum != nil && (number < *schema.Minimum || (schema.ExclusiveMinimum && number == *schema.Minimum)) {
+		return false
+	}
+	if schema.Maximum != nil && (number > *schema.Maximum || (schema.ExclusiveMaximum && number == *schema.Maximum)) {
+		return false
+	}
+	return true
+}
+
+// containsNumber reports whether the values hold the number, whatever their numeric type
+func containsNumber(values []any, number float64) bool {
+	for _, value := range values {
+		if v, ok := toFloat(value); ok && v == number {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package openapiv3
+
+import (
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/helper"
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/openapi"
+)
+
+// applyFieldBehavior marks the property of a field after its google.api.field_behavior
+// annotation, behaviors OpenAPI has no keyword for are kept as vendor extensions.
+// Field behavior specification:
+// https://google.aip.dev/203
+func applyFieldBehavior(property *openapi.Schema, field *protogen.Field) {
+	if helper.HasFieldBehavior(field, annotations.FieldBehavior_OUTPUT_ONLY) {
+		property.ReadOnly = true
+	}
+	if helper.HasFieldBehavior(field, annotations.FieldBehavior_INPUT_ONLY) {
+		property.WriteOnly = true
+	}
+	if helper.HasFieldBehavior(field, annotations.FieldBehavior_IMMUTABLE) {
+		property.Extensions = withExtension(property.Extensions, "x-immutable", true)
+	}
+	if helper.HasFieldBehavior(field, annotations.FieldBehavior_IDENTIFIER) {
+		property.Extensions = withExtension(property.Extensions, "x-identifier", true)
+	}
+}
+
+// isRequiredField reports whether the field is annotated as REQUIRED
+func isRequiredField(field *protogen.Field) bool {
+	return helper.HasFieldBehavior(field, annotations.FieldBehavior_REQUIRED)
+}
+
+// isRequiredFieldPath reports whether every field of a path is REQUIRED, a
+// nested query parameter such as filter.status needs its parents to be set
+func isRequiredFieldPath(fields []*protogen.Field) bool {
+	for _, field := range fields {
+		if !isRequiredField(field) {
+			return false
+		}
+	}
+	return true
+}
+
+// isOutputOnlyField reports whether the field is only set by the server, such
+// fields can't be sent as query parameters
+func isOutputOnlyField(field *protogen.Field) bool {
+	return helper.HasFieldBehavior(field, annotations.FieldBehavior_OUTPUT_ONLY)
+}
@@ -7,45 +7,47 @@ import (
 
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/proto"
-	"gopkg.in/yaml.v3"
 
 	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/helper"
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/httprule"
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/openapi"
 )
 
-// GenerateFile traverses all proto files and generates the OpenAPI specification file
-func GenerateFile(gen *protogen.Plugin, f *protogen.File) {
-	paths := make(map[string]map[string]any)
-	commonResp := map[string]any{
-		"type": "object",
-		"properties": map[string]any{
-			"code": map[string]any{
-				"type": "integer",
+// generateDocument generates the OpenAPI specification of the services, the
+// schemas of all messages they use are pulled into the document. Methods with
+// invalid HTTP bindings are reported to diags and left out.
+func generateDocument(services []*protogen.Service, opts *Options, diags *diagnostics) *openapi.Document {
+	commonResp := &openapi.Schema{
+		Type: openapi.Types{"object"},
+		Properties: map[string]*openapi.Schema{
+			"code": {
+				Type: openapi.Types{"integer"},
 			},
-			"message": map[string]any{
-				"type": "string",
+			"message": {
+				Type: openapi.Types{"string"},
 			},
-			"details": map[string]any{
-				"type": "array",
-				"items": map[string]any{
-					"type": "object",
-					"properties": map[string]any{
-						"@type": map[string]any{
-							"type": "string",
+			"details": {
+				Type: openapi.Types{"array"},
+				Items: &openapi.Schema{
+					Type: openapi.Types{"object"},
+					Properties: map[string]*openapi.Schema{
+						"@type": {
+							Type: openapi.Types{"string"},
 						},
-						"reason": map[string]any{
-							"type": "string",
+						"reason": {
+							Type: openapi.Types{"string"},
 						},
-						"domain": map[string]any{
-							"type": "string",
+						"domain": {
+							Type: openapi.Types{"string"},
 						},
-						"metadata": map[string]any{
-							"type": "object",
+						"metadata": {
+							Type: openapi.Types{"object"},
 						},
 					},
 				},
 			},
 		},
-		"example": map[string]any{
+		Example: map[string]any{
 			"code":    3,
 			"message": "must be at least 11 characters long",
 			"details": []map[string]any{
@@ -62,288 +64,541 @@ func GenerateFile(gen *protogen.Plugin, f *protogen.File) {
 	}
 
 	// Basic structure of the OpenAPI specification
-	openAPI := map[string]any{
-		"openapi": "3.0.0",
-		"info": map[string]any{
-			"title":       "Generated API",
-			"description": "API generated from protobufs",
-			"version":     "1.0.0",
+	doc := &openapi.Document{
+		OpenAPI: "3.0.0",
+		Info: &openapi.Info{
+			Title:       "Generated API",
+			Description: "API generated from protobufs",
+			Version:     "1.0.0",
 		},
-		"security": []map[string]any{
+		Security: []openapi.SecurityRequirement{
 			{
-				"BearerAuth": []any{},
+				"BearerAuth": []string{},
 			},
 		},
-		"paths": paths,
-		"components": map[string]any{
-			"schemas": map[string]any{
+		Paths: make(map[string]*openapi.PathItem),
+		Components: &openapi.Components{
+			Schemas: map[string]*openapi.Schema{
 				"BadRequest":          commonResp,
 				"Unauthorized":        commonResp,
 				"InternalServerError": commonResp,
 			},
-			"securitySchemes": map[string]any{
-				"BearerAuth": map[string]any{
-					"type":         "http",
-					"scheme":       "bearer",
-					"bearerFormat": "JWT",
+			SecuritySchemes: map[string]*openapi.SecurityScheme{
+				"BearerAuth": {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
 				},
 			},
 		},
 	}
 
-	servers := parseServersOption(gen)
-	if len(servers) > 0 {
-		openAPI["servers"] = servers
+	for _, server := range opts.Servers {
+		doc.Servers = append(doc.Servers, &openapi.Server{
+			URL:         server.URL,
+			Description: server.Description,
+		})
 	}
+	naming := opts.ParamNaming
 
 	allTags := map[string]string{}
 
-	// Traverse all proto files
-	for _, file := range gen.Files {
-		if file.Generate {
-			// Traverse each service in the file
-			for _, service := range file.Services {
-				svcName := GetServiceName(service)
-				allTags[svcName] = GetServiceDescription(service)
-				for _, method := range service.Methods {
-					// Generate OpenAPI path for each method under the service
-					operation := map[string]any{
-						"tags":        []string{svcName},
-						"operationId": fmt.Sprintf("%s_%s", service.GoName, method.GoName),
-						"responses":   getResponseBody(method.Output),
-					}
+	// Traverse each service
+	for _, service := range services {
+		svcName := GetServiceName(service)
+		allTags[svcName] = GetServiceDescription(service)
+		if allTags[svcName] == "" {
+			allTags[svcName] = getComments(opts, service.Comments)
+		}
+		for _, method := range service.Methods {
+			bindings, err := helper.GetHttpBindings(method)
+			if err != nil {
+				diags.errorf(method.Desc, "%v", err)
+				continue
+			}
 
-					// Check if skip_token is true
-					methodOpts := proto.GetExtension(method.Desc.Options(), E_Method).(*Method)
-					if methodOpts != nil && methodOpts.SkipToken {
-						operation["security"] = []map[string]any{}
+			// Each binding, including additional bindings, is a separate operation
+			for i, binding := range bindings {
+				tmpl, err := httprule.Parse(binding.Path)
+				if err != nil {
+					diags.errorf(method.Desc, "%s: %v", method.Desc.FullName(), err)
+					continue
+				}
+				// Path placeholders are renamed to match the parameter names
+				methodPath := tmpl.Path(func(fieldPath string) string {
+					if fields := helper.GetFieldPath(method.Input, fieldPath); fields != nil {
+						return getParameterName(fields, naming)
 					}
+					return fieldPath
+				})
 
-					addMessageSchema(openAPI, method.Output)
-					methodPath, httpMethod, bindings := helper.GetHttpMethodAndPath(method)
-					if httpMethod == "post" || httpMethod == "put" || httpMethod == "patch" {
-						operation["requestBody"] = getRequestBody(method.Input)
-						addMessageSchema(openAPI, method.Input)
-					}
+				operationID := fmt.Sprintf("%s_%s", service.GoName, method.GoName)
+				if i > 0 {
+					operationID = fmt.Sprintf("%s%d", operationID, i+1)
+				}
 
-					parameters := extractPathParameters(method.Input, methodPath, bindings)
-					if len(parameters) > 0 {
-						operation["parameters"] = parameters
-					}
+				// Routes differing only in their variable patterns share a path, the
+				// first operation bound to it is kept
+				pathItem, ok := doc.Paths[methodPath]
+				if !ok {
+					pathItem = &openapi.PathItem{}
+					doc.Paths[methodPath] = pathItem
+				}
+				if existing := pathItem.Operation(binding.Method); existing != nil {
+					diags.warnf(method.Desc, "%s %s of %s is already used by %s, the binding is left out",
+						strings.ToUpper(binding.Method), methodPath, operationID, existing.OperationID)
+					continue
+				}
 
-					if _, ok := paths[methodPath]; !ok {
-						paths[methodPath] = make(map[string]any)
-					}
-					paths[methodPath][httpMethod] = operation
+				// Generate OpenAPI path for each method under the service
+				summary, description := getSummaryAndDescription(opts, method)
+				operation := &openapi.Operation{
+					Tags:        []string{svcName},
+					Summary:     summary,
+					Description: description,
+					OperationID: operationID,
+					Responses:   getResponseBody(doc, opts, diags, method, binding.Method, binding.ResponseBody),
+					RequestBody: getRequestBody(doc, opts, diags, method, binding.Body, getPathKeys(tmpl)),
+					Parameters:  extractPathParameters(opts, diags, method, tmpl, binding.Body),
 				}
+
+				// Check if skip_token is true
+				methodOpts := proto.GetExtension(method.Desc.Options(), E_Method).(*Method)
+				if methodOpts != nil && methodOpts.SkipToken {
+					operation.Security = []openapi.SecurityRequirement{}
+				}
+
+				pathItem.SetOperation(binding.Method, operation)
 			}
 		}
 	}
 
 	// Tags specification:
 	// https://swagger.io/docs/specification/v3_0/grouping-operations-with-tags/
-	tags := make([]map[string]any, 0, len(allTags))
 	for tag, desc := range allTags {
-		tags = append(tags, map[string]any{
-			"name":        tag,
-			"description": desc,
+		doc.Tags = append(doc.Tags, &openapi.Tag{
+			Name:        tag,
+			Description: desc,
 		})
 	}
 	// sort tags by name
-	sort.Slice(tags, func(i, j int) bool {
-		return tags[i]["name"].(string) < tags[j]["name"].(string)
+	sort.Slice(doc.Tags, func(i, j int) bool {
+		return doc.Tags[i].Name < doc.Tags[j].Name
 	})
-	openAPI["tags"] = tags
 
-	// Generate OpenAPI YAML file
-	openAPIDocument, err := yaml.Marshal(openAPI)
-	if err != nil {
-		fmt.Println("Error marshalling OpenAPI document:", err)
+	applyOpenAPIVersion(doc, opts.OpenAPIVersion)
+	return doc
+}
+
+// addMessageSchema adds proto message types to OpenAPI components
+func addMessageSchema(doc *openapi.Document, opts *Options, diags *diagnostics, message *protogen.Message) {
+	addMessageSchemaWithout(doc, opts, diags, helper.GetSchemaName(message), message, nil)
+}
+
+// addMessageSchemaWithout adds a proto message type to OpenAPI components under
+// the given name, leaving out the fields in exclude, e.g. fields bound to the path
+func addMessageSchemaWithout(doc *openapi.Document, opts *Options, diags *diagnostics, schemaName string, message *protogen.Message, exclude map[string]struct{}) {
+	schemas := doc.Components.Schemas
+
+	// Well-known types used as method input or output keep their JSON mapping
+	if isWellKnownType(message) {
+		schema, example := getWellKnownPropertyAndExample(message, nil)
+		schema.Example = example
+		schemas[schemaName] = schema
 		return
 	}
 
-	generatedFile := gen.NewGeneratedFile(f.GeneratedFilenamePrefix+"_openapi.yaml", f.GoImportPath)
-	generatedFile.P(string(openAPIDocument))
-}
+	// Skip messages that are already added, this also stops recursive messages
+	if _, ok := schemas[schemaName]; ok {
+		return
+	}
 
-// addMessageSchema adds proto message types to OpenAPI components
-func addMessageSchema(openAPI map[string]any, message *protogen.Message) {
-	schemaName := helper.GetSchemaName(message)
-	if components, ok := openAPI["components"].(map[string]any); ok {
-		if schemas, ok := components["schemas"].(map[string]any); ok {
-			// Construct schema
-			schema := make(map[string]any)
-			schema["type"] = "object"
-			properties := make(map[string]any)
-			examples := make(map[string]any)
-
-			// Traverse fields and generate properties
-			for _, field := range message.Fields {
-				property, example := GetPropertyAndExample(field, func(message *protogen.Message) {
-					addMessageSchema(openAPI, message)
-				})
+	// Construct schema, it's registered before traversing fields for recursive messages
+	schema := &openapi.Schema{
+		Type:        openapi.Types{"object"},
+		Description: getComments(opts, message.Comments),
+	}
+	schemas[schemaName] = schema
+	applyProtovalidateMessageRules(schema, message)
+	properties := make(map[string]*openapi.Schema)
+	examples := make(map[string]any)
+	var requiredFields []string
 
-				examples[field.Desc.JSONName()] = example
-				properties[field.Desc.JSONName()] = property
-			}
+	// Traverse fields and generate properties
+	for _, field := range message.Fields {
+		if _, ok := exclude[string(field.Desc.Name())]; ok {
+			continue
+		}
+		checkExample(diags, field)
+		property, example := GetPropertyAndExample(field, func(message *protogen.Message) {
+			addMessageSchema(doc, opts, diags, message)
+		})
+		property.Title = getFieldTitle(field)
+		property.Description = getFieldDescription(opts, field)
+		applyFieldBehavior(property, field)
+		required := applyFieldRules(property, field)
+		if field.Desc.IsList() {
+			example = []any{example}
+		}
+		example, hasExample := fitExample(field, property, example)
 
-			// Add generated properties to schema
-			schema["properties"] = properties
-			if len(examples) > 0 {
-				schema["example"] = examples
+		if isOneofField(field) {
+			// Oneof members are described by oneOf variants, only the first one is used in the example
+			if field == field.Oneof.Fields[0] && hasExample {
+				examples[field.Desc.JSONName()] = example
 			}
+			continue
+		}
 
-			// Add schema to components/schemas
-			schemas[schemaName] = schema
+		if hasExample {
+			examples[field.Desc.JSONName()] = example
 		}
+		properties[field.Desc.JSONName()] = property
+		if required {
+			requiredFields = append(requiredFields, field.Desc.JSONName())
+		}
+	}
+
+	// Add generated properties to schema
+	schema.Properties = properties
+	schema.Required = requiredFields
+	if len(examples) > 0 {
+		schema.Example = examples
 	}
+	addOneofSchemas(doc, opts, diags, schema, message)
 }
 
-// parseServersOption parses the servers option from the plugin options
-func parseServersOption(gen *protogen.Plugin) []map[string]any {
-	parts := strings.Split(gen.Request.GetParameter(), ",")
-	servers := make([]map[string]any, 0)
-	for _, part := range parts {
-		if strings.HasPrefix(part, "servers=") {
-			for _, server := range strings.Split(strings.TrimPrefix(part, "servers="), ";") {
-				info := strings.Split(server, "|")
-				if len(info) == 2 {
-					servers = append(servers, map[string]any{
-						"url":         info[0],
-						"description": info[1],
-					})
-				} else {
-					servers = append(servers, map[string]any{
-						"url": info[0],
-					})
-				}
-			}
+// addSchema adds a named schema to OpenAPI components
+func addSchema(doc *openapi.Document, name string, schema *openapi.Schema) {
+	doc.Components.Schemas[name] = schema
+}
+
+// Parameter naming schemes of the param_naming option
+const (
+	// ParamNamingJSON names parameters and path placeholders after the JSON names of fields
+	ParamNamingJSON = "json"
+	// ParamNamingProto names parameters and path placeholders after the proto names of fields
+	ParamNamingProto = "proto"
+)
+
+// getRequestBody builds the request body from the google.api.http body selector,
+// "*" maps the whole message, a field name maps only that field and an empty
+// selector means there is no request body. Fields bound to the path are left
+// out of the body through a dedicated schema named after the request, e.g.
+// UpdateTripRequest_Body
+func getRequestBody(doc *openapi.Document, opts *Options, diags *diagnostics, method *protogen.Method, body string, pathKeys map[string]struct{}) *openapi.RequestBody {
+	if body == "" {
+		return nil
+	}
+
+	message := method.Input
+	bodyMessage, prefix := message, ""
+	var field *protogen.Field
+	if body != "*" {
+		if field = helper.GetFieldFromMessage(message, body); field == nil {
+			diags.errorf(method.Desc, "body field %q not found in %s", body, message.Desc.FullName())
+			return nil
 		}
+		bodyMessage, prefix = field.Message, body+"."
 	}
-	return servers
-}
 
-func getRequestBody(message *protogen.Message) map[string]any {
-	return map[string]any{
-		"content": map[string]any{
-			"application/json": map[string]any{
-				"schema": map[string]any{
-					"$ref": fmt.Sprintf("#/components/schemas/%s", helper.GetSchemaName(message)),
-				},
+	// Only fields of the body message itself can be left out, e.g. {id} with body "*"
+	// or {trip.id} with body "trip"
+	exclude := make(map[string]struct{})
+	for key := range pathKeys {
+		if name, ok := strings.CutPrefix(key, prefix); ok && !strings.Contains(name, ".") {
+			exclude[name] = struct{}{}
+		}
+	}
+
+	var schema *openapi.Schema
+	switch {
+	case len(exclude) > 0 && bodyMessage != nil && !isWellKnownType(bodyMessage) && (field == nil || !field.Desc.IsList()):
+		schemaName := getBodySchemaName(doc, helper.GetSchemaName(message)+"_Body", bodyMessage, exclude)
+		addMessageSchemaWithout(doc, opts, diags, schemaName, bodyMessage, exclude)
+		schema = openapi.NewRef(schemaName)
+	case field == nil:
+		addMessageSchema(doc, opts, diags, message)
+		schema = openapi.NewRef(helper.GetSchemaName(message))
+	default:
+		schema, _ = GetPropertyAndExample(field, func(message *protogen.Message) {
+			addMessageSchema(doc, opts, diags, message)
+		})
+	}
+
+	var description string
+	if field != nil {
+		description = getFieldDescription(opts, field)
+	}
+	return &openapi.RequestBody{
+		Description: description,
+		Content: map[string]*openapi.MediaType{
+			"application/json": {
+				Schema: schema,
 			},
 		},
-		"required": true,
+		Required: true,
 	}
 }
 
-func getResponseBody(message *protogen.Message) map[string]any {
-	return map[string]any{
-		"200": map[string]any{
-			"description": "OK",
-			"content": map[string]any{
-				"application/json": map[string]any{
-					"schema": map[string]any{
-						"$ref": fmt.Sprintf("#/components/schemas/%s", helper.GetSchemaName(message)),
-					},
+// getBodySchemaName returns the name of the body schema leaving out the fields
+// in exclude, bindings leaving out other fields get numbered names, e.g.
+// CreateTripRequest_Body2
+func getBodySchemaName(doc *openapi.Document, name string, message *protogen.Message, exclude map[string]struct{}) string {
+	schemaName := name
+	for i := 2; ; i++ {
+		schema, ok := doc.Components.Schemas[schemaName]
+		if !ok || hasSameProperties(schema, message, exclude) {
+			return schemaName
+		}
+		schemaName = fmt.Sprintf("%s%d", name, i)
+	}
+}
+
+// hasSameProperties reports whether the schema of the message has the
+// properties left when the fields in exclude are left out
+func hasSameProperties(schema *openapi.Schema, message *protogen.Message, exclude map[string]struct{}) bool {
+	count := 0
+	for _, field := range message.Fields {
+		if _, ok := exclude[string(field.Desc.Name())]; ok || isOneofField(field) {
+			continue
+		}
+		if _, ok := schema.Properties[field.Desc.JSONName()]; !ok {
+			return false
+		}
+		count++
+	}
+	return count == len(schema.Properties)
+}
+
+// getResponseBody builds the responses of an operation, when responseBody names
+// an output field only that field is returned instead of the whole message.
+// Responses to head requests have no content.
+func getResponseBody(doc *openapi.Document, opts *Options, diags *diagnostics, method *protogen.Method, httpMethod, responseBody string) map[string]*openapi.Response {
+	if httpMethod == "head" {
+		return map[string]*openapi.Response{
+			"200": {Description: "OK"},
+			"400": {Description: "Bad Request"},
+			"401": {Description: "Unauthorized"},
+			"500": {Description: "Internal Server Error"},
+		}
+	}
+
+	message := method.Output
+	var schema *openapi.Schema
+	if field := helper.GetFieldFromMessage(message, responseBody); field != nil {
+		schema, _ = GetPropertyAndExample(field, func(message *protogen.Message) {
+			addMessageSchema(doc, opts, diags, message)
+		})
+	} else {
+		if responseBody != "" {
+			diags.warnf(method.Desc, "response_body field %q not found in %s, the whole message is returned", responseBody, message.Desc.FullName())
+		}
+		addMessageSchema(doc, opts, diags, message)
+		schema = openapi.NewRef(helper.GetSchemaName(message))
+	}
+
+	return map[string]*openapi.Response{
+		"200": {
+			Description: "OK",
+			Content: map[string]*openapi.MediaType{
+				"application/json": {
+					Schema: schema,
 				},
 			},
 		},
-		"400": map[string]any{
-			"description": "Bad Request",
-			"content": map[string]any{
-				"application/json": map[string]any{
-					"schema": map[string]any{
-						"$ref": "#/components/schemas/BadRequest",
-					},
+		"400": {
+			Description: "Bad Request",
+			Content: map[string]*openapi.MediaType{
+				"application/json": {
+					Schema: openapi.NewRef("BadRequest"),
 				},
 			},
 		},
-		"401": map[string]any{
-			"description": "Unauthorized",
-			"content": map[string]any{
-				"application/json": map[string]any{
-					"schema": map[string]any{
-						"$ref": "#/components/schemas/Unauthorized",
-					},
+		"401": {
+			Description: "Unauthorized",
+			Content: map[string]*openapi.MediaType{
+				"application/json": {
+					Schema: openapi.NewRef("Unauthorized"),
 				},
 			},
 		},
-		"500": map[string]any{
-			"description": "Internal Server Error",
-			"content": map[string]any{
-				"application/json": map[string]any{
-					"schema": map[string]any{
-						"$ref": "#/components/schemas/InternalServerError",
-					},
+		"500": {
+			Description: "Internal Server Error",
+			Content: map[string]*openapi.MediaType{
+				"application/json": {
+					Schema: openapi.NewRef("InternalServerError"),
 				},
 			},
 		},
 	}
 }
 
-type extractTarget interface {
-	[]string | string
+// getPathKeys returns the field paths bound by the variables of a path template
+func getPathKeys(tmpl *httprule.Template) map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, variable := range tmpl.Variables() {
+		keys[variable.FieldPath] = struct{}{}
+	}
+	return keys
 }
 
-func extractKeys[T extractTarget](s T) map[string]struct{} {
-	keys := make(map[string]struct{})
-	switch v := any(s).(type) {
-	case []string:
-		for _, vv := range v {
-			// /api/v1/trips?page={page}&size={size}
-			parts := strings.Split(vv, "{")
-			for _, part := range parts[1:] {
-				end := strings.Index(part, "}")
-				if end != -1 {
-					keys[part[:end]] = struct{}{}
-				}
-			}
-		}
-	case string:
-		parts := strings.Split(v, "/")
-		for _, part := range parts {
-			if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
-				keys[part[1:len(part)-1]] = struct{}{}
-			}
+// getParameterName returns the dotted name of a path or query parameter bound
+// to the chain of fields, following the param_naming option
+func getParameterName(fields []*protogen.Field, naming string) string {
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if naming == ParamNamingProto {
+			names = append(names, string(field.Desc.Name()))
+		} else {
+			names = append(names, field.Desc.JSONName())
 		}
 	}
-	return keys
+	return strings.Join(names, ".")
 }
 
-func extractPathParameters(message *protogen.Message, uri string, bindings []string) []map[string]any {
-	var parameters []map[string]any
-	pathKeys := extractKeys(uri)
-	for _, field := range message.Fields {
-		// only add path parameters that are in the bindings
-		if _, ok := pathKeys[string(field.Desc.Name())]; !ok {
+// extractPathParameters returns the path parameters of the template followed by
+// the query parameters, unknown path variables are kept as string parameters
+func extractPathParameters(opts *Options, diags *diagnostics, method *protogen.Method, tmpl *httprule.Template, body string) []*openapi.Parameter {
+	message := method.Input
+	var parameters []*openapi.Parameter
+	for _, variable := range tmpl.Variables() {
+		// Variables may reference nested fields, e.g. {trip.id}
+		fields := helper.GetFieldPath(message, variable.FieldPath)
+		if fields == nil {
+			diags.warnf(method.Desc, "path variable %q matches no field of %s", variable.FieldPath, message.Desc.FullName())
+			// The placeholder stays in the path, a parameter keeps the document valid
+			parameters = append(parameters, &openapi.Parameter{
+				Name:     variable.FieldPath,
+				In:       "path",
+				Required: true,
+				Schema:   &openapi.Schema{Type: openapi.Types{"string"}},
+			})
 			continue
 		}
-		params := map[string]any{
-			"name":     field.Desc.JSONName(),
-			"in":       "path",
-			"required": true,
-		}
+		field := fields[len(fields)-1]
+		checkExample(diags, field)
 		property, example := GetPropertyAndExample(field, nil)
-		params["schema"] = property
-		params["example"] = example
-		parameters = append(parameters, params)
+		applyFieldRules(property, field)
+		// Variables matching several segments, e.g. {name=projects/*/trips/*}, are constrained by a pattern
+		if pattern := variable.Pattern(); pattern != "" {
+			property.Pattern = pattern
+		}
+		property.Title = getFieldTitle(field)
+		example, hasExample := fitExample(field, property, example)
+		if !hasExample {
+			example = nil
+		}
+		parameters = append(parameters, &openapi.Parameter{
+			Name:        getParameterName(fields, opts.ParamNaming),
+			In:          "path",
+			Description: getFieldDescription(opts, field),
+			Required:    true,
+			Schema:      property,
+			Example:     example,
+		})
+	}
+	// Top level wildcards, e.g. /v1/*/trips, match segments bound to no field
+	for _, wildcard := range tmpl.Wildcards() {
+		parameters = append(parameters, &openapi.Parameter{
+			Name:     wildcard.FieldPath,
+			In:       "path",
+			Required: true,
+			Schema:   &openapi.Schema{Type: openapi.Types{"string"}, Pattern: wildcard.Pattern()},
+		})
+	}
+
+	// With body "*" every field that isn't bound to the path is in the request body
+	if body == "*" {
+		return parameters
+	}
+	exclude := getPathKeys(tmpl)
+	if body != "" {
+		exclude[body] = struct{}{}
+	}
+	return append(parameters, extractQueryParameters(opts, diags, message, nil, exclude, map[string]bool{}, 0)...)
+}
+
+// queryParameterMaxDepth limits the recursion into message fields when they are
+// flattened into dotted query parameters
+const queryParameterMaxDepth = 5
+
+// extractQueryParameters turns the fields that aren't bound to the path or body
+// into query parameters like grpc-gateway does, nested fields use dotted names
+// (filter.status) and repeated fields repeat the parameter (?tag=a&tag=b)
+func extractQueryParameters(opts *Options, diags *diagnostics, message *protogen.Message, parents []*protogen.Field, exclude map[string]struct{}, visited map[string]bool, depth int) []*openapi.Parameter {
+	schemaName := helper.GetSchemaName(message)
+	if depth > queryParameterMaxDepth || visited[schemaName] {
+		return nil
 	}
+	visited[schemaName] = true
+	defer delete(visited, schemaName)
 
-	queryKeys := extractKeys(bindings)
+	var parameters []*openapi.Parameter
 	for _, field := range message.Fields {
-		// only add query parameters that are in the bindings
-		if _, ok := queryKeys[string(field.Desc.Name())]; !ok {
+		// exclude holds the dotted proto field paths bound to the path or body
+		fields := append(parents[:len(parents):len(parents)], field)
+		if _, ok := exclude[getParameterName(fields, ParamNamingProto)]; ok {
 			continue
 		}
-		params := map[string]any{
-			"name":     field.Desc.JSONName(),
-			"in":       "query",
-			"required": false,
+		// Maps can't be passed as query parameters, neither can fields set by the server
+		if field.Desc.IsMap() || isOutputOnlyField(field) {
+			continue
+		}
+		name := getParameterName(fields, opts.ParamNaming)
+		if field.Message != nil && !isWellKnownType(field.Message) {
+			// Only singular messages can be flattened into dotted parameters
+			if field.Desc.IsList() {
+				continue
+			}
+			parameters = append(parameters, extractQueryParameters(opts, diags, field.Message, fields, exclude, visited, depth+1)...)
+			continue
 		}
+
+		checkExample(diags, field)
 		property, example := GetPropertyAndExample(field, nil)
-		params["schema"] = property
-		params["example"] = example
+		if !isQueryProperty(property) {
+			continue
+		}
+		property.Title = getFieldTitle(field)
+		required := applyFieldRules(property, field)
+		if field.Desc.IsList() {
+			example = []any{example}
+		}
+		example, hasExample := fitExample(field, property, example)
+		if !hasExample {
+			example = nil
+		}
+		params := &openapi.Parameter{
+			Name:        name,
+			In:          "query",
+			Description: getFieldDescription(opts, field),
+			Required:    required && isRequiredFieldPath(parents),
+			Schema:      property,
+			Example:     example,
+		}
+		if field.Desc.IsList() {
+			// Parameter serialization specification:
+			// https://swagger.io/docs/specification/v3_0/serialization/
+			params.Style = "form"
+			params.Explode = true
+		}
 		parameters = append(parameters, params)
 	}
 	return parameters
 }
+
+// isQueryProperty reports whether the schema can be passed as a query parameter,
+// which excludes objects such as google.protobuf.Struct
+func isQueryProperty(property *openapi.Schema) bool {
+	if property == nil || len(property.Type) != 1 {
+		return false
+	}
+	switch property.Type[0] {
+	case "array":
+		return isQueryProperty(property.Items)
+	case "string", "integer", "number", "boolean":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,276 @@
+package openapiv3
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestGenerateDocuments(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		golden  string
+	}{
+		{
+			name:    "openapi 3.0",
+			version: OpenAPIVersion30,
+			golden:  "pet_3.0.yaml",
+		},
+		{
+			name:    "openapi 3.1",
+			version: OpenAPIVersion31,
+			golden:  "pet_3.1.yaml",
+		},
+		{
+			name:    "swagger 2.0",
+			version: SwaggerVersion20,
+			golden:  "pet_2.0.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.OpenAPIVersion = tt.version
+			documents, err := GenerateDocuments(newPetDescriptorSet(), []string{"pet/v1/pet.proto"}, opts)
+			if err != nil {
+				t.Fatalf("GenerateDocuments() error: %v", err)
+			}
+			if len(documents) != 1 {
+				t.Fatalf("GenerateDocuments() returned %d documents, want 1", len(documents))
+			}
+			got, err := documents[0].Marshal(OutputFormatYAML)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", tt.golden)
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("document differs from %s, run go test -update to see the changes:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+// newPetDescriptorSet returns the descriptors of pet/v1/pet.proto and its
+// imports, written in proto it reads:
+//
+//	service PetService {
+//	  rpc GetPet(GetPetRequest) returns (Pet) {
+//	    option (google.api.http) = {get: "/v1/pets/{id}"};
+//	  }
+//	  rpc UpdatePet(UpdatePetRequest) returns (Pet) {
+//	    option (google.api.http) = {post: "/v1/pets/{id}" body: "*"};
+//	  }
+//	}
+//	message Pet {
+//	  option (openapiv3.message).discriminator = "kind";
+//	  string kind = 1;
+//	  oneof pet {
+//	    Cat cat = 2;
+//	    Dog dog = 3;
+//	  }
+//	  google.protobuf.Timestamp born_at = 4;
+//	}
+//	message Cat {
+//	  string name = 1 [(validatex.rules).string = {min_len: 3, max_len: 20}];
+//	}
+//	message Dog {
+//	  string owner_email = 1 [(validatex.rules).string.email = true];
+//	}
+//	message GetPetRequest {
+//	  string id = 1;
+//	}
+//	message UpdatePetRequest {
+//	  string id = 1;
+//	  Pet pet = 2;
+//	}
+func newPetDescriptorSet() *descriptorpb.FileDescriptorSet {
+	openapiv3File := protodesc.ToFileDescriptorProto(File_openapiv3_proto)
+	openapiv3File.Name = proto.String("openapiv3/openapiv3.proto")
+
+	getPet := &descriptorpb.MethodOptions{}
+	proto.SetExtension(getPet, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{Get: "/v1/pets/{id}"},
+	})
+	updatePet := &descriptorpb.MethodOptions{}
+	proto.SetExtension(updatePet, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Post{Post: "/v1/pets/{id}"},
+		Body:    "*",
+	})
+	pet := &descriptorpb.MessageOptions{}
+	proto.SetExtension(pet, E_Message, &Message{Discriminator: "kind"})
+
+	// The name has a length of 3 to 20, the owner an email
+	name := protowire.AppendTag(nil, 2, protowire.VarintType)
+	name = protowire.AppendVarint(name, 3)
+	name = protowire.AppendTag(name, 3, protowire.VarintType)
+	name = protowire.AppendVarint(name, 20)
+	email := protowire.AppendTag(nil, 1, protowire.VarintType)
+	email = protowire.AppendVarint(email, 1)
+
+	petFile := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("pet/v1/pet.proto"),
+		Package: proto.String("pet.v1"),
+		Syntax:  proto.String("proto3"),
+		Dependency: []string{
+			"google/api/annotations.proto",
+			"google/protobuf/timestamp.proto",
+			"openapiv3/openapiv3.proto",
+			"validatex/validatex.proto",
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("PetService"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{
+					Name:       proto.String("GetPet"),
+					InputType:  proto.String(".pet.v1.GetPetRequest"),
+					OutputType: proto.String(".pet.v1.Pet"),
+					Options:    getPet,
+				},
+				{
+					Name:       proto.String("UpdatePet"),
+					InputType:  proto.String(".pet.v1.UpdatePetRequest"),
+					OutputType: proto.String(".pet.v1.Pet"),
+					Options:    updatePet,
+				},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Pet"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					newField("kind", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					withOneof(newField("cat", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".pet.v1.Cat"), 0),
+					withOneof(newField("dog", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".pet.v1.Dog"), 0),
+					newField("born_at", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp"),
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("pet")}},
+				Options:   pet,
+			},
+			{
+				Name: proto.String("Cat"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					withValidatexString(newField("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""), name),
+				},
+			},
+			{
+				Name: proto.String("Dog"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					withValidatexString(newField("owner_email", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""), email),
+				},
+			},
+			{
+				Name: proto.String("GetPetRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					newField("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				},
+			},
+			{
+				Name: proto.String("UpdatePetRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					newField("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					newField("pet", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".pet.v1.Pet"),
+				},
+			},
+		},
+	}
+
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+		protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto),
+		protodesc.ToFileDescriptorProto(annotations.File_google_api_http_proto),
+		protodesc.ToFileDescriptorProto(annotations.File_google_api_annotations_proto),
+		openapiv3File,
+		newValidatexFile(),
+		petFile,
+	}}
+}
+
+// newValidatexFile returns the string rules of validatex/validatex.proto, the
+// validatex Go module isn't a dependency so the options are set as unknown fields
+func newValidatexFile() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("validatex/validatex.proto"),
+		Package:    proto.String("validatex"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("StringRules"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					newField("email", 1, descriptorpb.FieldDescriptorProto_TYPE_BOOL, ""),
+					newField("min_len", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+					newField("max_len", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+				},
+			},
+			{
+				Name: proto.String("FieldRules"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					withOneof(newField("string", 1, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".validatex.StringRules"), 0),
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("type")}},
+			},
+		},
+		Extension: []*descriptorpb.FieldDescriptorProto{{
+			Name:     proto.String("rules"),
+			Number:   proto.Int32(70000),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+			TypeName: proto.String(".validatex.FieldRules"),
+			Extendee: proto.String(".google.protobuf.FieldOptions"),
+		}},
+	}
+}
+
+// newField returns an optional field, typeName is set for messages
+func newField(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+	field := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   typ.Enum(),
+	}
+	if typeName != "" {
+		field.TypeName = proto.String(typeName)
+	}
+	return field
+}
+
+// withOneof adds the field to the oneof at the index
+func withOneof(field *descriptorpb.FieldDescriptorProto, index int32) *descriptorpb.FieldDescriptorProto {
+	field.OneofIndex = proto.Int32(index)
+	return field
+}
+
+// withValidatexString sets the validatex string rules of the field, given in
+// the wire format of StringRules
+func withValidatexString(field *descriptorpb.FieldDescriptorProto, stringRules []byte) *descriptorpb.FieldDescriptorProto {
+	rules := protowire.AppendTag(nil, 1, protowire.BytesType)
+	rules = protowire.AppendBytes(rules, stringRules)
+	b := protowire.AppendTag(nil, 70000, protowire.BytesType)
+	b = protowire.AppendBytes(b, rules)
+	field.Options = &descriptorpb.FieldOptions{}
+	field.Options.ProtoReflect().SetUnknown(b)
+	return field
+}
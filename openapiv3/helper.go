@@ -1,10 +1,10 @@
 package openapiv3
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/helper"
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/openapi"
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
@@ -68,67 +68,119 @@ func getExample[T openAPITypes](field *protogen.Field, defValue T) T {
 	}
 }
 
+// checkExample warns about an example option its field can't use, getExample
+// falls back to the default example for these values
+func checkExample(diags *diagnostics, field *protogen.Field) {
+	opt := proto.GetExtension(field.Desc.Options(), E_Example).(*Example)
+	if opt == nil {
+		return
+	}
+	val := opt.GetValue()
+
+	var valid bool
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		_, err := strconv.ParseBool(val)
+		valid = err == nil
+	case protoreflect.EnumKind:
+		if field.Enum.Desc.FullName() == "google.protobuf.NullValue" {
+			diags.warnf(field.Desc, "example is ignored on google.protobuf.NullValue field %s", field.Desc.Name())
+			return
+		}
+		for _, value := range helper.GetEnumValues(field.Enum) {
+			valid = valid || value == val
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Uint32Kind,
+		protoreflect.Sfixed32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Uint64Kind,
+		protoreflect.Sfixed64Kind, protoreflect.Fixed64Kind:
+		_, err := strconv.Atoi(val)
+		valid = err == nil
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		_, err := strconv.ParseFloat(val, 64)
+		valid = err == nil
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if field.Desc.IsMap() || !isWellKnownType(field.Message) {
+			diags.warnf(field.Desc, "example is ignored on message field %s", field.Desc.Name())
+			return
+		}
+		valid = isValidWellKnownExample(field.Message.Desc.FullName(), val)
+	default:
+		valid = true
+	}
+	if !valid {
+		diags.warnf(field.Desc, "invalid example %q for field %s", val, field.Desc.Name())
+	}
+}
+
 type nestedMessageCallback func(*protogen.Message)
 
-func GetPropertyAndExample(field *protogen.Field, nestedMessageCallback nestedMessageCallback) (map[string]interface{}, any) {
+func GetPropertyAndExample(field *protogen.Field, nestedMessageCallback nestedMessageCallback) (*openapi.Schema, any) {
 	var (
-		property = make(map[string]interface{})
+		property = &openapi.Schema{}
 		example  any
 	)
 
 	switch field.Desc.Kind() {
 	case protoreflect.BoolKind:
-		property["type"] = "boolean"
+		property.Type = openapi.Types{"boolean"}
 		example = getExample(field, true)
 	case protoreflect.EnumKind:
-		property["type"] = "string"
-		property["format"] = "enum"
+		if field.Enum.Desc.FullName() == "google.protobuf.NullValue" {
+			// NullValue is always encoded as JSON null
+			property.Nullable = true
+			example = nil
+			break
+		}
+		property.Type = openapi.Types{"string"}
+		property.Format = "enum"
 		// Enum specification:
 		// https://swagger.io/docs/specification/v3_0/data-models/enums/
-		property["enum"] = helper.GetEnumValues(field.Enum)
-		example = getExample(field, property["enum"].([]string)[0])
+		values := helper.GetEnumValues(field.Enum)
+		for _, value := range values {
+			property.Enum = append(property.Enum, value)
+		}
+		example = getExample(field, values[0])
 	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Uint32Kind,
 		protoreflect.Sfixed32Kind, protoreflect.Fixed32Kind:
-		property["type"] = "integer"
-		property["format"] = "int32"
+		property.Type = openapi.Types{"integer"}
+		property.Format = "int32"
 		example = getExample(field, 0)
 	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Uint64Kind,
 		protoreflect.Sfixed64Kind, protoreflect.Fixed64Kind:
-		property["type"] = "integer"
-		property["format"] = "int64"
+		property.Type = openapi.Types{"integer"}
+		property.Format = "int64"
 		example = getExample(field, 0)
 	case protoreflect.FloatKind:
-		property["type"] = "number"
-		property["format"] = "float"
+		property.Type = openapi.Types{"number"}
+		property.Format = "float"
 		example = getExample(field, 0.0)
 	case protoreflect.DoubleKind:
-		property["type"] = "number"
-		property["format"] = "double"
+		property.Type = openapi.Types{"number"}
+		property.Format = "double"
 		example = getExample(field, 0.0)
 	case protoreflect.StringKind:
-		property["type"] = "string"
+		property.Type = openapi.Types{"string"}
 		example = getExample(field, "")
 	case protoreflect.BytesKind:
-		property["type"] = "string"
-		property["format"] = "byte" // Or use "binary" if needed for base64 encoding
+		property.Type = openapi.Types{"string"}
+		property.Format = "byte" // Or use "binary" if needed for base64 encoding
 		example = getExample(field, "")
 	case protoreflect.MessageKind, protoreflect.GroupKind:
-		if helper.GetSchemaName(field.Message) == "google.protobuf.Timestamp" {
-			// This is google.protobuf.Timestamp, treat it as a date-time string
-			property["type"] = "integer"
-			property["format"] = "int32"
-			example = getExample(field, 1741589979)
+		if isWellKnownType(field.Message) {
+			// Well-known types have a special JSON representation, e.g. Timestamp is a date-time string
+			property, example = getWellKnownPropertyAndExample(field.Message, field)
 		} else {
 			// Otherwise, treat it as a regular message and add a reference to the schema
 			if nestedMessageCallback != nil {
 				nestedMessageCallback(field.Message)
-				property["$ref"] = fmt.Sprintf("#/components/schemas/%s", helper.GetSchemaName(field.Message))
+				property = openapi.NewRef(helper.GetSchemaName(field.Message))
 				// Generate a proper example object for nested messages instead of null
 				example = generateExampleForMessage(field.Message)
 			}
 		}
 	default:
-		property["type"] = "string"
+		property.Type = openapi.Types{"string"}
 		example = ""
 	}
 
@@ -136,7 +188,7 @@ func GetPropertyAndExample(field *protogen.Field, nestedMessageCallback nestedMe
 	if field.Desc.IsMap() {
 		// For protobuf maps, we need to determine the value type from the map entry message
 		// Maps in protobuf are compiled to repeated fields with a synthetic message containing key/value fields
-		var valueProperty map[string]any
+		var valueProperty *openapi.Schema
 		var valueExample any
 		
 		if field.Message != nil {
@@ -146,19 +198,19 @@ func GetPropertyAndExample(field *protogen.Field, nestedMessageCallback nestedMe
 				valueProperty, valueExample = GetPropertyAndExample(valueField, nestedMessageCallback)
 			} else {
 				// Fallback to string type if we can't find the value field
-				valueProperty = map[string]any{"type": "string"}
+				valueProperty = &openapi.Schema{Type: openapi.Types{"string"}}
 				valueExample = "value"
 			}
 		} else {
 			// Fallback to string type
-			valueProperty = map[string]any{"type": "string"}
+			valueProperty = &openapi.Schema{Type: openapi.Types{"string"}}
 			valueExample = "value"
 		}
 		
 		// Create the proper OpenAPI map representation
-		property = map[string]any{
-			"type":                 "object",
-			"additionalProperties": valueProperty,
+		property = &openapi.Schema{
+			Type:                 openapi.Types{"object"},
+			AdditionalProperties: &openapi.AdditionalProperties{Schema: valueProperty},
 		}
 		
 		// Create example with proper key-value structure
@@ -167,9 +219,9 @@ func GetPropertyAndExample(field *protogen.Field, nestedMessageCallback nestedMe
 			"key2": valueExample,
 		}
 	} else if field.Desc.Cardinality() == protoreflect.Repeated {
-		property = map[string]any{
-			"type":  "array",
-			"items": property,
+		property = &openapi.Schema{
+			Type:  openapi.Types{"array"},
+			Items: property,
 		}
 	}
 
@@ -196,12 +248,16 @@ func generateExampleForMessageWithVisited(message *protogen.Message, visited map
 	defer func() { delete(visited, schemaName) }() // Clean up after processing
 
 	for _, field := range message.Fields {
+		// Like the message schema, only the first member of a oneof is set
+		if isOneofField(field) && field != field.Oneof.Fields[0] {
+			continue
+		}
 		var fieldExample any
 
 		switch field.Desc.Kind() {
 		case protoreflect.MessageKind, protoreflect.GroupKind:
-			if helper.GetSchemaName(field.Message) == "google.protobuf.Timestamp" {
-				fieldExample = getExample(field, 1741589979)
+			if isWellKnownType(field.Message) {
+				_, fieldExample = getWellKnownPropertyAndExample(field.Message, field)
 			} else {
 				// Generate nested example recursively
 				fieldExample = generateExampleForMessageWithVisited(field.Message, visited)
@@ -219,8 +275,20 @@ func generateExampleForMessageWithVisited(message *protogen.Message, visited map
 			fieldExample = []any{fieldExample}
 		}
 
+		// The example meets the validation rules like the one of the message schema
+		property, _ := GetPropertyAndExample(field, nil)
+		applyFieldRules(property, field)
+		fieldExample, ok := fitExample(field, property, fieldExample)
+		if !ok {
+			continue
+		}
 		example[field.Desc.JSONName()] = fieldExample
 	}
 
+	// The discriminator value matches the member that is set
+	if discriminator, _ := getDiscriminator(message); discriminator != nil {
+		example[discriminator.field.Desc.JSONName()] = discriminator.values[getOneofs(message)[0].Fields[0]]
+	}
+
 	return example
 }
@@ -0,0 +1,180 @@
+package openapiv3
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/openapi"
+)
+
+// isOneofField reports whether the field belongs to a real oneof,
+// synthetic oneofs generated for proto3 optional fields are ignored
+func isOneofField(field *protogen.Field) bool {
+	return field.Oneof != nil && !field.Oneof.Desc.IsSynthetic()
+}
+
+// getOneofs returns the real oneofs of the message
+func getOneofs(message *protogen.Message) []*protogen.Oneof {
+	var oneofs []*protogen.Oneof
+	for _, oneof := range message.Oneofs {
+		if !oneof.Desc.IsSynthetic() {
+			oneofs = append(oneofs, oneof)
+		}
+	}
+	return oneofs
+}
+
+// discriminator is the field of a message telling which member of its oneof is
+// set, it's named by the discriminator message option
+type discriminator struct {
+	field *protogen.Field
+	// values are the discriminator values of the oneof members
+	values map[*protogen.Field]string
+}
+
+// getDiscriminator returns the discriminator of the message, nil when the option
+// isn't set. The option names a string or enum field of the message by its proto
+// or JSON name, string fields take the JSON names of the members as values and
+// enum fields the value named after each member, e.g. CAT or KIND_CAT for cat.
+func getDiscriminator(message *protogen.Message) (*discriminator, error) {
+	msgOpts := proto.GetExtension(message.Desc.Options(), E_Message).(*Message)
+	name := msgOpts.GetDiscriminator()
+	if name == "" {
+		return nil, nil
+	}
+
+	oneofs := getOneofs(message)
+	if len(oneofs) != 1 {
+		return nil, fmt.Errorf("discriminator %q of %s must describe a single oneof", name, message.Desc.FullName())
+	}
+	var field *protogen.Field
+	for _, f := range message.Fields {
+		if string(f.Desc.Name()) == name || f.Desc.JSONName() == name {
+			field = f
+		}
+	}
+	if field == nil || field.Desc.IsList() || isOneofField(field) ||
+		(field.Desc.Kind() != protoreflect.StringKind && field.Desc.Kind() != protoreflect.EnumKind) {
+		return nil, fmt.Errorf("discriminator %q of %s must be a string or enum field outside the oneof", name, message.Desc.FullName())
+	}
+
+	d := &discriminator{field: field, values: make(map[*protogen.Field]string)}
+	for _, member := range oneofs[0].Fields {
+		if field.Enum == nil {
+			d.values[member] = member.Desc.JSONName()
+			continue
+		}
+		memberName := strings.ToUpper(string(member.Desc.Name()))
+		for _, value := range field.Enum.Values {
+			valueName := string(value.Desc.Name())
+			if valueName == memberName || strings.HasSuffix(valueName, "_"+memberName) {
+				d.values[member] = valueName
+				break
+			}
+		}
+		if _, ok := d.values[member]; !ok {
+			return nil, fmt.Errorf("discriminator %q of %s has no enum value for %s", name, message.Desc.FullName(), member.Desc.Name())
+		}
+	}
+	return d, nil
+}
+
+// addOneofSchemas turns the oneofs of a message into oneOf variants, each variant
+// requires exactly one member so that setting several members is rejected.
+// With a discriminator the variants are component schemas requiring the value
+// of the discriminator field, a member must then be set.
+// Oneof specification:
+// https://swagger.io/docs/specification/v3_0/data-models/oneof-anyof-allof-not/
+func addOneofSchemas(doc *openapi.Document, opts *Options, diags *diagnostics, schema *openapi.Schema, message *protogen.Message) {
+	oneofs := getOneofs(message)
+	if len(oneofs) == 0 {
+		return
+	}
+
+	discriminator, err := getDiscriminator(message)
+	if err != nil {
+		diags.errorf(message.Desc, "%v", err)
+	}
+
+	groups := make([]*openapi.Schema, 0, len(oneofs))
+	for _, oneof := range oneofs {
+		variants := make([]*openapi.Schema, 0, len(oneof.Fields))
+		mapping := make(map[string]string)
+		for _, field := range oneof.Fields {
+			property, _ := GetPropertyAndExample(field, func(message *protogen.Message) {
+				addMessageSchema(doc, opts, diags, message)
+			})
+			property.Title = getFieldTitle(field)
+			property.Description = getFieldDescription(opts, field)
+			applyFieldBehavior(property, field)
+			applyFieldRules(property, field)
+			name := field.Desc.JSONName()
+			variant := &openapi.Schema{
+				Type: openapi.Types{"object"},
+				Properties: map[string]*openapi.Schema{
+					name: property,
+				},
+				Required: []string{name},
+			}
+			if discriminator == nil {
+				variants = append(variants, variant)
+				continue
+			}
+
+			// Discriminator mappings must reference component schemas
+			value := discriminator.values[field]
+			discriminatorName := discriminator.field.Desc.JSONName()
+			variant.Properties[discriminatorName] = &openapi.Schema{
+				Type: openapi.Types{"string"},
+				Enum: []any{value},
+			}
+			variant.Required = []string{discriminatorName, name}
+			variantName := getOneofVariantName(field)
+			addSchema(doc, variantName, variant)
+			ref := openapi.NewRef(variantName)
+			mapping[value] = ref.Ref
+			variants = append(variants, ref)
+		}
+
+		// Oneofs that aren't required may also have no member set, which can't
+		// be told by a discriminator value
+		if discriminator == nil && !isRequiredOneof(oneof) {
+			members := make([]*openapi.Schema, 0, len(oneof.Fields))
+			for _, field := range oneof.Fields {
+				members = append(members, &openapi.Schema{Required: []string{field.Desc.JSONName()}})
+			}
+			variants = append(variants, &openapi.Schema{Not: &openapi.Schema{AnyOf: members}})
+		}
+
+		group := &openapi.Schema{OneOf: variants}
+		if discriminator != nil {
+			group.Discriminator = &openapi.Discriminator{
+				PropertyName: discriminator.field.Desc.JSONName(),
+				Mapping:      mapping,
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	// The example sets the first member, so it carries the matching discriminator value
+	if example, ok := schema.Example.(map[string]any); ok && discriminator != nil {
+		example[discriminator.field.Desc.JSONName()] = discriminator.values[oneofs[0].Fields[0]]
+	}
+
+	if len(groups) == 1 {
+		schema.OneOf = groups[0].OneOf
+		schema.Discriminator = groups[0].Discriminator
+		return
+	}
+	schema.AllOf = groups
+}
+
+// getOneofVariantName returns the schema name of a oneof member, it reuses the
+// generated Go wrapper type name which is unique within the package
+func getOneofVariantName(field *protogen.Field) string {
+	return fmt.Sprintf("%s.%s", field.Desc.ParentFile().Package(), field.GoIdent.GoName)
+}
@@ -0,0 +1,180 @@
+package openapiv3
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Options is the plugin configuration parsed from the comma separated key=value
+// parameter, e.g. --openapiv3_opt=openapi_out_path=./example,output_strategy=merged
+type Options struct {
+	// OutPath is the directory the documents are written to, relative to the protoc output directory
+	OutPath string
+	// OutputStrategy groups services into documents: merged, per_file, per_service or per_package
+	OutputStrategy string
+	// OutputFilename is the name of the merged document
+	OutputFilename string
+	// OutputFormat is the serialization of the documents
+	OutputFormat string
+	// OpenAPIVersion is the OpenAPI version of the documents: 3.0, 3.1 or 2.0 for Swagger
+	OpenAPIVersion string
+	// ParamNaming names parameters and path placeholders after json or proto field names
+	ParamNaming string
+	// Servers are the servers of the documents
+	Servers []Server
+	// Paths is the protoc paths option naming the per file, service or package
+	// documents: import or source_relative
+	Paths string
+	// IgnoreCommentPrefixes leaves the comment lines starting with one of the
+	// prefixes out of descriptions, e.g. buf:lint:ignore or @internal
+	IgnoreCommentPrefixes []string
+	// WarningsAsErrors fails the generation on warnings
+	WarningsAsErrors bool
+	// OnWarning receives the warnings, they're written to stderr when it's nil
+	OnWarning func(Diagnostic)
+}
+
+// Server is an entry of the servers option written as url|description
+type Server struct {
+	URL         string
+	Description string
+}
+
+// Output formats of the output_format option
+const (
+	// OutputFormatYAML writes YAML documents
+	OutputFormatYAML = "yaml"
+	// OutputFormatJSON writes pretty-printed JSON documents
+	OutputFormatJSON = "json"
+	// OutputFormatBoth writes every document as YAML and JSON
+	OutputFormatBoth = "both"
+)
+
+// DefaultOptions returns the options used when no parameter is given
+func DefaultOptions() *Options {
+	return &Options{
+		OutputStrategy: OutputMerged,
+		OutputFilename: defaultOutputFilename,
+		OutputFormat:   OutputFormatYAML,
+		OpenAPIVersion: OpenAPIVersion30,
+		ParamNaming:    ParamNamingJSON,
+	}
+}
+
+// ParseOptions parses the plugin parameter, parameters handled by protogen
+// itself such as paths or M mappings are ignored
+func ParseOptions(parameter string) (*Options, error) {
+	opts := DefaultOptions()
+	for _, param := range strings.Split(parameter, ",") {
+		if param == "" || isProtogenParam(param) {
+			continue
+		}
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed option %q, expected key=value", param)
+		}
+
+		switch key {
+		case "openapi_out_path":
+			opts.OutPath = value
+		case "output_strategy":
+			opts.OutputStrategy = value
+		case "output_filename":
+			opts.OutputFilename = value
+		case "output_format":
+			opts.OutputFormat = value
+		case "openapi_version":
+			opts.OpenAPIVersion = value
+		case "param_naming":
+			opts.ParamNaming = value
+		case "servers":
+			opts.Servers = append(opts.Servers, parseServers(value)...)
+		case "ignore_comment_prefixes":
+			opts.IgnoreCommentPrefixes = append(opts.IgnoreCommentPrefixes, parseList(value)...)
+		case "warnings_as_errors":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid warnings_as_errors %q, expected true or false", value)
+			}
+			opts.WarningsAsErrors = b
+		case "paths":
+			// Also handled by protogen, it's kept for generation from descriptor sets
+			opts.Paths = value
+		default:
+			return nil, fmt.Errorf("unknown option %q", key)
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// Validate checks the option values, errors are named after the plugin parameter keys
+func (o *Options) Validate() error {
+	if path.IsAbs(o.OutPath) || strings.HasPrefix(path.Clean(o.OutPath), "..") {
+		return fmt.Errorf("openapi_out_path %q must be relative to the output directory", o.OutPath)
+	}
+	if err := checkOptionValue("output_strategy", o.OutputStrategy, OutputMerged, OutputPerFile, OutputPerService, OutputPerPackage); err != nil {
+		return err
+	}
+	if o.OutputFilename == "" {
+		return fmt.Errorf("output_filename must not be empty")
+	}
+	if err := checkOptionValue("output_format", o.OutputFormat, OutputFormatYAML, OutputFormatJSON, OutputFormatBoth); err != nil {
+		return err
+	}
+	if err := checkOptionValue("openapi_version", o.OpenAPIVersion, OpenAPIVersion30, OpenAPIVersion31, SwaggerVersion20); err != nil {
+		return err
+	}
+	if err := checkOptionValue("param_naming", o.ParamNaming, ParamNamingJSON, ParamNamingProto); err != nil {
+		return err
+	}
+	if o.Paths != "" {
+		return checkOptionValue("paths", o.Paths, "import", "source_relative")
+	}
+	return nil
+}
+
+// isProtogenParam reports whether the parameter is handled by protogen
+func isProtogenParam(param string) bool {
+	key, _, _ := strings.Cut(param, "=")
+	switch key {
+	case "module", "annotate_code", "default_api_level":
+		return true
+	}
+	return strings.HasPrefix(key, "M") || strings.HasPrefix(key, "apilevelM")
+}
+
+func checkOptionValue(key, value string, allowed ...string) error {
+	for _, v := range allowed {
+		if value == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid %s %q, expected one of %s", key, value, strings.Join(allowed, ", "))
+}
+
+// parseList parses values separated by semicolons, empty values are dropped
+func parseList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ";") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// parseServers parses servers separated by semicolons, e.g.
+// https://localhost:8000|Dev Server;https://localhost:9000|Prod Server
+func parseServers(value string) []Server {
+	var servers []Server
+	for _, server := range strings.Split(value, ";") {
+		url, description, _ := strings.Cut(server, "|")
+		servers = append(servers, Server{URL: url, Description: description})
+	}
+	return servers
+}
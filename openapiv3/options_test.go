@@ -0,0 +1,123 @@
+package openapiv3
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseOptions(t *testing.T) {
+	withDefaults := func(update func(opts *Options)) *Options {
+		opts := DefaultOptions()
+		update(opts)
+		return opts
+	}
+
+	tests := []struct {
+		name      string
+		parameter string
+		want      *Options
+		wantErr   bool
+	}{
+		{
+			name:      "empty",
+			parameter: "",
+			want:      DefaultOptions(),
+		},
+		{
+			name:      "all options",
+			parameter: "openapi_out_path=./docs,output_strategy=per_service,output_filename=api,output_format=both,openapi_version=3.1,param_naming=proto,paths=source_relative,warnings_as_errors=true",
+			want: withDefaults(func(opts *Options) {
+				opts.OutPath = "./docs"
+				opts.OutputStrategy = OutputPerService
+				opts.OutputFilename = "api"
+				opts.OutputFormat = OutputFormatBoth
+				opts.OpenAPIVersion = OpenAPIVersion31
+				opts.ParamNaming = ParamNamingProto
+				opts.Paths = "source_relative"
+				opts.WarningsAsErrors = true
+			}),
+		},
+		{
+			name:      "servers",
+			parameter: "servers=https://localhost:8000|Dev Server;https://localhost:9000,servers=https://example.com|Prod",
+			want: withDefaults(func(opts *Options) {
+				opts.Servers = []Server{
+					{URL: "https://localhost:8000", Description: "Dev Server"},
+					{URL: "https://localhost:9000"},
+					{URL: "https://example.com", Description: "Prod"},
+				}
+			}),
+		},
+		{
+			name:      "ignore comment prefixes",
+			parameter: "ignore_comment_prefixes=buf:lint:ignore; @internal;",
+			want: withDefaults(func(opts *Options) {
+				opts.IgnoreCommentPrefixes = []string{"buf:lint:ignore", "@internal"}
+			}),
+		},
+		{
+			name:      "protogen parameters",
+			parameter: "Mtrip.proto=example.com/trip,module=example.com,annotate_code,output_format=json",
+			want: withDefaults(func(opts *Options) {
+				opts.OutputFormat = OutputFormatJSON
+			}),
+		},
+		{
+			name:      "malformed",
+			parameter: "output_format",
+			wantErr:   true,
+		},
+		{
+			name:      "unknown option",
+			parameter: "output=yaml",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid value",
+			parameter: "output_strategy=per_method",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid paths",
+			parameter: "paths=absolute",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid bool",
+			parameter: "warnings_as_errors=yes",
+			wantErr:   true,
+		},
+		{
+			name:      "empty filename",
+			parameter: "output_filename=",
+			wantErr:   true,
+		},
+		{
+			name:      "absolute out path",
+			parameter: "openapi_out_path=/tmp/docs",
+			wantErr:   true,
+		},
+		{
+			name:      "out path outside the output directory",
+			parameter: "openapi_out_path=../docs",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := ParseOptions(tt.parameter)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseOptions(%q) = %+v, want an error", tt.parameter, opts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOptions(%q) error: %v", tt.parameter, err)
+			}
+			if !reflect.DeepEqual(opts, tt.want) {
+				t.Errorf("ParseOptions(%q) = %+v, want %+v", tt.parameter, opts, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package openapiv3
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"gopkg.in/yaml.v3"
+
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/openapi"
+)
+
+// Output strategies of the output_strategy option
+const (
+	// OutputMerged writes a single document with every service
+	OutputMerged = "merged"
+	// OutputPerFile writes a document for each proto file with services
+	OutputPerFile = "per_file"
+	// OutputPerService writes a document for each service
+	OutputPerService = "per_service"
+	// OutputPerPackage writes a document for each proto package with services
+	OutputPerPackage = "per_package"
+)
+
+// defaultOutputFilename is the name of the merged document
+const defaultOutputFilename = "openapi.yaml"
+
+// outputUnit is a generated document and the services it describes
+type outputUnit struct {
+	filename string
+	services []*protogen.Service
+}
+
+// GeneratedDocument is a generated document before it's serialized, it can be
+// inspected and modified through the object model
+type GeneratedDocument struct {
+	// Filename is the path of the document relative to the output directory,
+	// the extension is replaced for each output format
+	Filename string
+	// OpenAPI is the document for OpenAPI 3.0 and 3.1
+	OpenAPI *openapi.Document
+	// Swagger is the document for Swagger 2.0
+	Swagger *openapi.Swagger
+}
+
+// File is a serialized document
+type File struct {
+	// Name is the path of the file relative to the output directory
+	Name    string
+	Content []byte
+}
+
+// Marshal serializes the document as yaml or json
+func (d *GeneratedDocument) Marshal(format string) ([]byte, error) {
+	if d.Swagger != nil {
+		return marshalDocument(d.Swagger, format)
+	}
+	return marshalDocument(d.OpenAPI, format)
+}
+
+// Generate writes the OpenAPI specification of the files to generate, the
+// services are grouped into documents by the output_strategy option
+func Generate(gen *protogen.Plugin) error {
+	opts, err := ParseOptions(gen.Request.GetParameter())
+	if err != nil {
+		return err
+	}
+
+	files, err := generateFiles(gen, opts)
+	if err != nil {
+		return err
+	}
+	return writeFiles(gen, files)
+}
+
+// GenerateFile writes the OpenAPI specification of the services of a single
+// file next to it, e.g. trip_openapi.yaml, problems are reported through
+// gen.Error.
+//
+// Deprecated: use Generate, which groups the services of the files to generate
+// by the output_strategy option.
+func GenerateFile(gen *protogen.Plugin, f *protogen.File) {
+	opts, err := ParseOptions(gen.Request.GetParameter())
+	if err != nil {
+		gen.Error(err)
+		return
+	}
+
+	units := []outputUnit{{filename: f.GeneratedFilenamePrefix + "_openapi.yaml", services: f.Services}}
+	documents, err := generateUnitDocuments(units, opts)
+	if err != nil {
+		gen.Error(err)
+		return
+	}
+	files, err := serializeDocuments(documents, opts)
+	if err != nil {
+		gen.Error(err)
+		return
+	}
+	if err := writeFiles(gen, files); err != nil {
+		gen.Error(err)
+	}
+}
+
+// writeFiles adds the serialized documents to the plugin response
+func writeFiles(gen *protogen.Plugin, files []*File) error {
+	for _, file := range files {
+		generatedFile := gen.NewGeneratedFile(file.Name, "")
+		if _, err := generatedFile.Write(file.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateDocuments generates a document for each output unit of the files to
+// generate, the problems found in every unit are returned together as Diagnostics
+func generateDocuments(gen *protogen.Plugin, opts *Options) ([]*GeneratedDocument, error) {
+	return generateUnitDocuments(getOutputUnits(gen, opts), opts)
+}
+
+// generateUnitDocuments generates the documents of the output units
+func generateUnitDocuments(units []outputUnit, opts *Options) ([]*GeneratedDocument, error) {
+	diags := &diagnostics{}
+	var documents []*GeneratedDocument
+	for _, unit := range units {
+		documents = append(documents, newGeneratedDocument(unit, opts, diags))
+	}
+	if err := diags.result(opts); err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+// newGeneratedDocument generates the document of an output unit in the
+// version of the openapi_version option
+func newGeneratedDocument(unit outputUnit, opts *Options, diags *diagnostics) *GeneratedDocument {
+	doc := generateDocument(unit.services, opts, diags)
+	document := &GeneratedDocument{Filename: path.Join(opts.OutPath, unit.filename)}
+	if opts.OpenAPIVersion == SwaggerVersion20 {
+		document.Swagger = convertToSwagger(doc, diags)
+	} else {
+		document.OpenAPI = doc
+	}
+	return document
+}
+
+// generateFiles serializes the generated documents in every output format
+func generateFiles(gen *protogen.Plugin, opts *Options) ([]*File, error) {
+	documents, err := generateDocuments(gen, opts)
+	if err != nil {
+		return nil, err
+	}
+	return serializeDocuments(documents, opts)
+}
+
+// serializeDocuments serializes the documents in every output format, files
+// end with a newline
+func serializeDocuments(documents []*GeneratedDocument, opts *Options) ([]*File, error) {
+	var files []*File
+	for _, document := range documents {
+		for _, format := range getOutputFormats(opts.OutputFormat) {
+			content, err := document.Marshal(format)
+			if err != nil {
+				return nil, fmt.Errorf("marshalling %s: %w", document.Filename, err)
+			}
+			if !bytes.HasSuffix(content, []byte("\n")) {
+				content = append(content, '\n')
+			}
+			files = append(files, &File{
+				Name:    getOutputFilename(document.Filename, format),
+				Content: content,
+			})
+		}
+	}
+	return files, nil
+}
+
+// getOutputFormats returns the formats written for the output_format option
+func getOutputFormats(outputFormat string) []string {
+	if outputFormat == OutputFormatBoth {
+		return []string{OutputFormatYAML, OutputFormatJSON}
+	}
+	return []string{outputFormat}
+}
+
+// getOutputFilename replaces the extension of the filename with the one of the format
+func getOutputFilename(filename string, format string) string {
+	switch path.Ext(filename) {
+	case ".yaml", ".yml", ".json":
+		filename = strings.TrimSuffix(filename, path.Ext(filename))
+	}
+	return filename + "." + format
+}
+
+// marshalDocument serializes an *openapi.Document or *openapi.Swagger, both
+// formats keep the field order of the model and sort map keys so the output is stable
+func marshalDocument(document any, format string) ([]byte, error) {
+	if format == OutputFormatJSON {
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(document); err != nil {
+			return nil, err
+		}
+		return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+	}
+	return yaml.Marshal(document)
+}
+
+// getOutputUnits groups the services of the files to generate into documents,
+// units without services are skipped
+func getOutputUnits(gen *protogen.Plugin, opts *Options) []outputUnit {
+	var files []*protogen.File
+	for _, f := range gen.Files {
+		if f.Generate && len(f.Services) > 0 {
+			files = append(files, f)
+		}
+	}
+
+	var units []outputUnit
+	switch opts.OutputStrategy {
+	case OutputMerged:
+		unit := outputUnit{filename: opts.OutputFilename}
+		for _, f := range files {
+			unit.services = append(unit.services, f.Services...)
+		}
+		if len(unit.services) > 0 {
+			units = append(units, unit)
+		}
+	case OutputPerFile:
+		for _, f := range files {
+			units = append(units, outputUnit{
+				filename: f.GeneratedFilenamePrefix + "_openapi.yaml",
+				services: f.Services,
+			})
+		}
+	case OutputPerService:
+		for _, f := range files {
+			for _, service := range f.Services {
+				units = append(units, outputUnit{
+					filename: path.Join(path.Dir(f.GeneratedFilenamePrefix), string(service.Desc.FullName())+"_openapi.yaml"),
+					services: []*protogen.Service{service},
+				})
+			}
+		}
+	case OutputPerPackage:
+		// The document is written next to the first file of the package
+		packages := make(map[string]int)
+		for _, f := range files {
+			pkg := string(f.Desc.Package())
+			i, ok := packages[pkg]
+			if !ok {
+				i = len(units)
+				packages[pkg] = i
+				units = append(units, outputUnit{
+					filename: path.Join(path.Dir(f.GeneratedFilenamePrefix), pkg+"_openapi.yaml"),
+				})
+			}
+			units[i].services = append(units[i].services, f.Services...)
+		}
+	}
+	return units
+}
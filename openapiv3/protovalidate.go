@@ -0,0 +1,174 @@
+package openapiv3
+
+import (
+	"regexp"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/helper"
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/openapi"
+)
+
+// Options holding the protovalidate rules, they're read from the descriptors so
+// that the buf Go module isn't needed
+const (
+	protovalidateField   protoreflect.FullName = "buf.validate.field"
+	protovalidateOneof   protoreflect.FullName = "buf.validate.oneof"
+	protovalidateMessage protoreflect.FullName = "buf.validate.message"
+)
+
+// protovalidateIgnoreAlways is the Ignore value turning the rules of a field off
+const protovalidateIgnoreAlways = 3
+
+// applyProtovalidateRules adds the constraints of the protovalidate rules of a
+// field to its property, it reports whether the rules require the field to be set.
+// protovalidate:
+// https://buf.build/docs/protovalidate/
+func applyProtovalidateRules(property *openapi.Schema, field *protogen.Field) bool {
+	rules := getDynamicExtension(field.Desc.ParentFile(), field.Desc.Options(), protovalidateField)
+	if rules == nil {
+		return false
+	}
+	return applyProtovalidateFieldRules(property, rules, field)
+}
+
+// applyProtovalidateFieldRules applies FieldRules to a property, the rules of
+// list items and map values are applied to the items and values schemas
+func applyProtovalidateFieldRules(property *openapi.Schema, rules protoreflect.Message, field *protogen.Field) bool {
+	if value, ok := getDynamicValue(rules, "ignore"); ok && value.Enum() == protovalidateIgnoreAlways {
+		return false
+	}
+	if cel := getCELRules(rules); len(cel) > 0 {
+		property.Extensions = withExtension(property.Extensions, "x-cel", cel)
+	}
+
+	if stringRules := getDynamicMessage(rules, "string"); stringRules != nil {
+		applyStringRules(property, stringRules)
+		applyProtovalidateStringRules(property, stringRules)
+	}
+	if bytesRules := getDynamicMessage(rules, "bytes"); bytesRules != nil {
+		applyBytesRules(property, bytesRules)
+	}
+	for _, name := range numberRuleNames {
+		if numberRules := getDynamicMessage(rules, name); numberRules != nil {
+			applyNumberRules(property, numberRules)
+			applyNotInRules(property, numberRules)
+		}
+	}
+	if enumRules := getDynamicMessage(rules, "enum"); enumRules != nil && field.Enum != nil {
+		applyEnumRules(property, enumRules, field.Enum)
+		if value, ok := getDynamicValue(enumRules, "const"); ok {
+			if enumValue := field.Enum.Desc.Values().ByNumber(value.Enum()); enumValue != nil {
+				property.Enum = []any{string(enumValue.Name())}
+			}
+		}
+	}
+	if repeatedRules := getDynamicMessage(rules, "repeated"); repeatedRules != nil {
+		applyRepeatedRules(property, repeatedRules)
+		if items := getDynamicMessage(repeatedRules, "items"); items != nil && property.Items != nil {
+			applyProtovalidateFieldRules(property.Items, items, field)
+		}
+	}
+	if mapRules := getDynamicMessage(rules, "map"); mapRules != nil {
+		property.MinProperties = getDynamicUint(mapRules, "min_pairs")
+		property.MaxProperties = getDynamicUint(mapRules, "max_pairs")
+		values := getDynamicMessage(mapRules, "values")
+		if values != nil && field.Desc.IsMap() && property.AdditionalProperties != nil && property.AdditionalProperties.Schema != nil {
+			valueField := helper.GetFieldFromMessage(field.Message, "value")
+			applyProtovalidateFieldRules(property.AdditionalProperties.Schema, values, valueField)
+		}
+	}
+
+	value, ok := getDynamicValue(rules, "required")
+	return ok && value.Bool()
+}
+
+// applyProtovalidateStringRules applies the string rules validatex doesn't have,
+// a prefix or suffix becomes the pattern when there's none
+func applyProtovalidateStringRules(property *openapi.Schema, rules protoreflect.Message) {
+	if value, ok := getDynamicValue(rules, "const"); ok {
+		property.Enum = []any{value.String()}
+	}
+	if value, ok := getDynamicValue(rules, "uri_ref"); ok && value.Bool() {
+		property.Format = "uri-reference"
+	}
+	applyNotInRules(property, rules)
+
+	prefix, hasPrefix := getDynamicValue(rules, "prefix")
+	suffix, hasSuffix := getDynamicValue(rules, "suffix")
+	if property.Pattern != "" || (!hasPrefix && !hasSuffix) {
+		return
+	}
+	pattern := "^" + regexp.QuoteMeta(prefix.String()) + ".*"
+	if hasSuffix {
+		pattern += regexp.QuoteMeta(suffix.String()) + "$"
+	}
+	property.Pattern = pattern
+}
+
+// applyBytesRules sets the lengths of bytes, which are base64 encoded in JSON,
+// so the bounds are the lengths of the encoded strings
+func applyBytesRules(property *openapi.Schema, rules protoreflect.Message) {
+	base64Length := func(length *uint64) *uint64 {
+		if length == nil {
+			return nil
+		}
+		return newUint((*length + 2) / 3 * 4)
+	}
+	if length := getDynamicUint(rules, "len"); length != nil {
+		property.MinLength, property.MaxLength = base64Length(length), base64Length(length)
+	}
+	if length := getDynamicUint(rules, "min_len"); length != nil {
+		property.MinLength = base64Length(length)
+	}
+	if length := getDynamicUint(rules, "max_len"); length != nil {
+		property.MaxLength = base64Length(length)
+	}
+}
+
+// applyNotInRules rejects the values listed by the not_in rule
+func applyNotInRules(property *openapi.Schema, rules protoreflect.Message) {
+	if values := getDynamicList(rules, "not_in"); len(values) > 0 {
+		property.Not = &openapi.Schema{Enum: values}
+	}
+}
+
+// applyProtovalidateMessageRules adds the CEL rules of a message to its schema
+func applyProtovalidateMessageRules(schema *openapi.Schema, message *protogen.Message) {
+	rules := getDynamicExtension(message.Desc.ParentFile(), message.Desc.Options(), protovalidateMessage)
+	if cel := getCELRules(rules); len(cel) > 0 {
+		schema.Extensions = withExtension(schema.Extensions, "x-cel", cel)
+	}
+}
+
+// isRequiredOneof reports whether a member of the oneof must be set, which is
+// only the case when it's marked with (buf.validate.oneof).required
+func isRequiredOneof(oneof *protogen.Oneof) bool {
+	rules := getDynamicExtension(oneof.Desc.ParentFile(), oneof.Desc.Options(), protovalidateOneof)
+	value, ok := getDynamicValue(rules, "required")
+	return ok && value.Bool()
+}
+
+// getCELRules returns the CEL expressions of field or message rules with their
+// id and message, they're kept as the x-cel extension since JSON Schema can't
+// express them
+func getCELRules(rules protoreflect.Message) []any {
+	value, ok := getDynamicValue(rules, "cel")
+	if !ok {
+		return nil
+	}
+	list := value.List()
+	cel := make([]any, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		rule := list.Get(i).Message()
+		entry := make(map[string]any)
+		for _, name := range []protoreflect.Name{"id", "message", "expression"} {
+			if value, ok := getDynamicValue(rule, name); ok && value.String() != "" {
+				entry[string(name)] = value.String()
+			}
+		}
+		cel = append(cel, entry)
+	}
+	return cel
+}
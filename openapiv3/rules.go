@@ -0,0 +1,21 @@
+package openapiv3
+
+import (
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/openapi"
+)
+
+// applyFieldRules adds the constraints of the validatex and protovalidate rules
+// of a field to its property, it reports whether the field is required by its
+// rules or its google.api.field_behavior
+func applyFieldRules(property *openapi.Schema, field *protogen.Field) bool {
+	required := isRequiredField(field)
+	if applyValidatexRules(property, field) {
+		required = true
+	}
+	if applyProtovalidateRules(property, field) {
+		required = true
+	}
+	return required
+}
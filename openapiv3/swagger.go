@@ -0,0 +1,287 @@
+package openapiv3
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/openapi"
+)
+
+// SwaggerVersion20 of the openapi_version option converts documents to Swagger 2.0
+const SwaggerVersion20 = "2.0"
+
+// convertToSwagger converts an OpenAPI 3.0 document to Swagger 2.0, constructs
+// that can't be converted are dropped and reported as warnings. The schemas of
+// the document are reused and rewritten in place.
+// Swagger 2.0 specification:
+// https://swagger.io/specification/v2/
+func convertToSwagger(doc *openapi.Document, diags *diagnostics) *openapi.Swagger {
+	swagger := &openapi.Swagger{
+		Swagger:  "2.0",
+		Info:     doc.Info,
+		Consumes: []string{"application/json"},
+		Produces: []string{"application/json"},
+		Paths:    make(map[string]openapi.SwaggerPathItem),
+		Security: doc.Security,
+		Tags:     doc.Tags,
+	}
+
+	// servers are replaced by a single host, base path and the schemes
+	if len(doc.Servers) > 1 {
+		diags.warnf(nil, "servers: Swagger 2.0 supports a single host, only the host and base path of %s are used", doc.Servers[0].URL)
+	}
+	for i, server := range doc.Servers {
+		u, err := url.Parse(server.URL)
+		if err != nil || u.Host == "" {
+			diags.warnf(nil, "servers: %s is not an absolute URL", server.URL)
+			continue
+		}
+		if i == 0 {
+			swagger.Host = u.Host
+			swagger.BasePath = strings.TrimSuffix(u.Path, "/")
+		}
+		if !contains(swagger.Schemes, u.Scheme) {
+			swagger.Schemes = append(swagger.Schemes, u.Scheme)
+		}
+	}
+
+	if doc.Components != nil {
+		schemas := doc.Components.Schemas
+		for _, name := range sortedKeys(schemas) {
+			if schema, ok := schemas[name]; ok {
+				mergeOneofVariants(schema, schemas, "definitions/"+name, diags)
+			}
+		}
+		for _, name := range sortedKeys(schemas) {
+			convertSchemaToSwagger(schemas[name], "definitions/"+name, diags)
+		}
+		swagger.Definitions = schemas
+		if doc.Components.SecuritySchemes != nil {
+			swagger.SecurityDefinitions = convertSecuritySchemes(doc.Components.SecuritySchemes, diags)
+		}
+	}
+
+	for _, path := range sortedKeys(doc.Paths) {
+		pathItem := doc.Paths[path]
+		swagger.Paths[path] = make(openapi.SwaggerPathItem)
+		for _, method := range pathItem.Methods() {
+			operation := pathItem.Operation(method)
+			// Swagger 2.0 path items have no trace operation
+			if method == "trace" {
+				diags.warnf(nil, "paths/%s/%s: trace operations are not supported by Swagger 2.0, %s was removed", path, method, operation.OperationID)
+				continue
+			}
+			swagger.Paths[path][method] = convertOperationToSwagger(operation, fmt.Sprintf("paths/%s/%s", path, method), diags)
+		}
+		if len(swagger.Paths[path]) == 0 {
+			delete(swagger.Paths, path)
+		}
+	}
+	return swagger
+}
+
+// convertOperationToSwagger moves the request body into a body parameter and
+// the response schemas out of their media types
+func convertOperationToSwagger(operation *openapi.Operation, location string, diags *diagnostics) *openapi.SwaggerOperation {
+	converted := &openapi.SwaggerOperation{
+		Tags:        operation.Tags,
+		Summary:     operation.Summary,
+		Description: operation.Description,
+		OperationID: operation.OperationID,
+		Responses:   make(map[string]*openapi.SwaggerResponse),
+		Deprecated:  operation.Deprecated,
+		Security:    operation.Security,
+		Extensions:  operation.Extensions,
+	}
+
+	for _, parameter := range operation.Parameters {
+		converted.Parameters = append(converted.Parameters, convertParameterToSwagger(parameter, location, diags))
+	}
+	if requestBody := operation.RequestBody; requestBody != nil {
+		schema := getJSONContentSchema(requestBody.Content)
+		convertSchemaToSwagger(schema, location+"/requestBody", diags)
+		converted.Parameters = append(converted.Parameters, &openapi.SwaggerParameter{
+			Name:        "body",
+			In:          "body",
+			Description: requestBody.Description,
+			Required:    requestBody.Required,
+			Schema:      schema,
+		})
+	}
+
+	for _, code := range sortedKeys(operation.Responses) {
+		response := operation.Responses[code]
+		schema := getJSONContentSchema(response.Content)
+		convertSchemaToSwagger(schema, fmt.Sprintf("%s/responses/%s", location, code), diags)
+		converted.Responses[code] = &openapi.SwaggerResponse{
+			Description: response.Description,
+			Schema:      schema,
+		}
+	}
+	return converted
+}
+
+// convertParameterToSwagger converts a path or query parameter, its schema is
+// written into the parameter itself
+func convertParameterToSwagger(parameter *openapi.Parameter, location string, diags *diagnostics) *openapi.SwaggerParameter {
+	convertSchemaToSwagger(parameter.Schema, fmt.Sprintf("%s/parameters/%s", location, parameter.Name), diags)
+	// Parameters are described by their own keys, a title isn't one of them
+	if parameter.Schema != nil {
+		parameter.Schema.Title = ""
+	}
+	converted := &openapi.SwaggerParameter{
+		Name:        parameter.Name,
+		In:          parameter.In,
+		Description: parameter.Description,
+		Required:    parameter.Required,
+		Schema:      parameter.Schema,
+		Extensions:  parameter.Extensions,
+	}
+	if parameter.Example != nil {
+		converted.Extensions = withExtension(converted.Extensions, "x-example", parameter.Example)
+	}
+	// Repeated query parameters (?tag=a&tag=b) are written with the multi collection format
+	if parameter.Schema != nil && parameter.Schema.Type.Is("array") && parameter.Explode {
+		converted.CollectionFormat = "multi"
+	}
+	return converted
+}
+
+// convertSchemaToSwagger rewrites a schema and its subschemas in place, keywords
+// Swagger 2.0 doesn't know are kept as vendor extensions or removed with a warning
+func convertSchemaToSwagger(schema *openapi.Schema, location string, diags *diagnostics) {
+	walkSchema(schema, func(schema *openapi.Schema) {
+		schema.Ref = strings.Replace(schema.Ref, "#/components/schemas/", "#/definitions/", 1)
+
+		if schema.Nullable {
+			schema.Extensions = withExtension(schema.Extensions, "x-nullable", true)
+			schema.Nullable = false
+		}
+		if schema.WriteOnly {
+			schema.Extensions = withExtension(schema.Extensions, "x-writeOnly", true)
+			schema.WriteOnly = false
+		}
+		if schema.Deprecated {
+			schema.Extensions = withExtension(schema.Extensions, "x-deprecated", true)
+			schema.Deprecated = false
+		}
+
+		if schema.OneOf != nil {
+			schema.OneOf = nil
+			diags.warnf(nil, "%s: oneOf is not supported by Swagger 2.0 and was removed", location)
+		}
+		if schema.AnyOf != nil {
+			schema.AnyOf = nil
+			diags.warnf(nil, "%s: anyOf is not supported by Swagger 2.0 and was removed", location)
+		}
+		if schema.Not != nil {
+			schema.Not = nil
+			diags.warnf(nil, "%s: not is not supported by Swagger 2.0 and was removed", location)
+		}
+		if schema.Discriminator != nil {
+			schema.Discriminator = nil
+			diags.warnf(nil, "%s: discriminator is not supported by Swagger 2.0 and was removed", location)
+		}
+	})
+}
+
+// mergeOneofVariants replaces the oneOf groups of a message schema by the
+// properties of their variants, which become optional since Swagger 2.0 can't
+// express that only one of them may be set
+func mergeOneofVariants(schema *openapi.Schema, definitions map[string]*openapi.Schema, location string, diags *diagnostics) {
+	var groups [][]*openapi.Schema
+	if schema.OneOf != nil {
+		groups = append(groups, schema.OneOf)
+	}
+	var allOf []*openapi.Schema
+	for _, subschema := range schema.AllOf {
+		if subschema.OneOf != nil && len(subschema.AllOf) == 0 && len(subschema.Properties) == 0 {
+			groups = append(groups, subschema.OneOf)
+		} else {
+			allOf = append(allOf, subschema)
+		}
+	}
+	if len(groups) == 0 {
+		return
+	}
+
+	if schema.Properties == nil {
+		schema.Properties = make(map[string]*openapi.Schema)
+	}
+	for _, variants := range groups {
+		for _, variant := range variants {
+			// Variant components only exist for the discriminator and aren't needed anymore
+			if variant.Ref != "" {
+				name := strings.TrimPrefix(variant.Ref, "#/components/schemas/")
+				variant = definitions[name]
+				delete(definitions, name)
+			}
+			if variant == nil {
+				continue
+			}
+			for name, property := range variant.Properties {
+				if _, ok := schema.Properties[name]; !ok {
+					schema.Properties[name] = property
+				}
+			}
+		}
+	}
+	schema.OneOf = nil
+	schema.AllOf = allOf
+
+	// The discriminator is a field of the message, its property is kept as is
+	schema.Discriminator = nil
+	diags.warnf(nil, "%s: oneOf is not supported by Swagger 2.0, its variants were merged into optional properties", location)
+}
+
+// convertSecuritySchemes maps OpenAPI 3.0 security schemes to security definitions,
+// bearer authentication becomes an Authorization header API key
+func convertSecuritySchemes(securitySchemes map[string]*openapi.SecurityScheme, diags *diagnostics) map[string]*openapi.SecurityScheme {
+	definitions := make(map[string]*openapi.SecurityScheme)
+	for _, name := range sortedKeys(securitySchemes) {
+		scheme := securitySchemes[name]
+		switch {
+		case scheme.Type == "http" && scheme.Scheme == "bearer":
+			definitions[name] = &openapi.SecurityScheme{
+				Type:        "apiKey",
+				Name:        "Authorization",
+				In:          "header",
+				Description: "Bearer token, e.g. \"Bearer <token>\"",
+			}
+		case scheme.Type == "http" && scheme.Scheme == "basic":
+			definitions[name] = &openapi.SecurityScheme{Type: "basic", Description: scheme.Description}
+		case scheme.Type == "apiKey" && scheme.In != "cookie":
+			definitions[name] = scheme
+		default:
+			diags.warnf(nil, "securitySchemes/%s: security scheme can't be converted to Swagger 2.0 and was removed", name)
+		}
+	}
+	return definitions
+}
+
+// getJSONContentSchema returns the application/json schema of a request body or response
+func getJSONContentSchema(content map[string]*openapi.MediaType) *openapi.Schema {
+	if mediaType, ok := content["application/json"]; ok {
+		return mediaType.Schema
+	}
+	return nil
+}
+
+// withExtension sets a vendor extension, creating the extensions if needed
+func withExtension(extensions map[string]any, name string, value any) map[string]any {
+	if extensions == nil {
+		extensions = make(map[string]any)
+	}
+	extensions[name] = value
+	return extensions
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package openapiv3
+
+import (
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/openapi"
+)
+
+// validatexRules is the field option holding the validatex rules, it's read
+// from the descriptors so that the validatex Go module isn't needed
+const validatexRules protoreflect.FullName = "validatex.rules"
+
+// numberRuleNames name the rules of the numeric field types, validatex and
+// protovalidate use the same names
+var numberRuleNames = []protoreflect.Name{
+	"int32", "int64", "uint32", "uint64", "sint32", "sint64",
+	"fixed32", "fixed64", "sfixed32", "sfixed64", "float", "double",
+}
+
+// applyValidatexRules adds the constraints of the validatex rules of a field to
+// its property, it reports whether the rules require the field to be set.
+// validatex:
+// https://github.com/protoc-gen/protoc-gen-validatex
+func applyValidatexRules(property *openapi.Schema, field *protogen.Field) bool {
+	rules := getDynamicExtension(field.Desc.ParentFile(), field.Desc.Options(), validatexRules)
+	if rules == nil {
+		return false
+	}
+
+	// Scalar rules of repeated fields constrain their items
+	items := property
+	if field.Desc.IsList() && property.Items != nil {
+		items = property.Items
+	}
+
+	var required bool
+	if stringRules := getDynamicMessage(rules, "string"); stringRules != nil {
+		required = applyStringRules(items, stringRules)
+	}
+	for _, name := range numberRuleNames {
+		if numberRules := getDynamicMessage(rules, name); numberRules != nil {
+			applyNumberRules(items, numberRules)
+		}
+	}
+	if enumRules := getDynamicMessage(rules, "enum"); enumRules != nil && field.Enum != nil {
+		applyEnumRules(items, enumRules, field.Enum)
+	}
+	if repeatedRules := getDynamicMessage(rules, "repeated"); repeatedRules != nil {
+		required = applyRepeatedRules(property, repeatedRules) || required
+	}
+	return required
+}
+
+// applyStringRules sets the length, pattern, format and allowed values of a
+// string, non-empty strings are required
+func applyStringRules(property *openapi.Schema, rules protoreflect.Message) bool {
+	for _, name := range []protoreflect.Name{"exact_len", "len"} {
+		if length := getDynamicUint(rules, name); length != nil {
+			property.MinLength, property.MaxLength = length, length
+		}
+	}
+	if length := getDynamicUint(rules, "min_len"); length != nil {
+		property.MinLength = length
+	}
+	if length := getDynamicUint(rules, "max_len"); length != nil {
+		property.MaxLength = length
+	}
+	if pattern, ok := getDynamicValue(rules, "pattern"); ok {
+		property.Pattern = pattern.String()
+	}
+	for _, format := range []protoreflect.Name{"email", "uuid", "uri", "hostname", "ipv4", "ipv6"} {
+		if value, ok := getDynamicValue(rules, format); ok && value.Bool() {
+			property.Format = string(format)
+		}
+	}
+	if values := getDynamicList(rules, "in"); len(values) > 0 {
+		property.Enum = values
+	}
+
+	value, ok := getDynamicValue(rules, "non_empty")
+	if !ok || !value.Bool() {
+		return false
+	}
+	if property.MinLength == nil || *property.MinLength == 0 {
+		property.MinLength = newUint(1)
+	}
+	return true
+}
+
+// applyNumberRules sets the bounds and allowed values of a number, gt and lt
+// are exclusive bounds
+func applyNumberRules(property *openapi.Schema, rules protoreflect.Message) {
+	if value, ok := getDynamicNumber(rules, "gte"); ok {
+		property.Minimum, property.ExclusiveMinimum = &value, false
+	}
+	if value, ok := getDynamicNumber(rules, "gt"); ok {
+		property.Minimum, property.ExclusiveMinimum = &value, true
+	}
+	if value, ok := getDynamicNumber(rules, "lte"); ok {
+		property.Maximum, property.ExclusiveMaximum = &value, false
+	}
+	if value, ok := getDynamicNumber(rules, "lt"); ok {
+		property.Maximum, property.ExclusiveMaximum = &value, true
+	}
+	if value, ok := getDynamicValue(rules, "const"); ok {
+		property.Enum = []any{value.Interface()}
+	}
+	if values := getDynamicList(rules, "in"); len(values) > 0 {
+		property.Enum = values
+	}
+}
+
+// applyEnumRules restricts the values of an enum to the numbers listed by the
+// in rule and removes the numbers listed by not_in
+func applyEnumRules(property *openapi.Schema, rules protoreflect.Message, enum *protogen.Enum) {
+	in, notIn := getDynamicList(rules, "in"), getDynamicList(rules, "not_in")
+	if len(in) == 0 && len(notIn) == 0 {
+		return
+	}
+	var values []any
+	for _, value := range enum.Values {
+		number := int32(value.Desc.Number())
+		if (len(in) == 0 || containsValue(in, number)) && !containsValue(notIn, number) {
+			values = append(values, string(value.Desc.Name()))
+		}
+	}
+	property.Enum = values
+}
+
+// applyRepeatedRules sets the number of items of a list, non-empty lists are required
+func applyRepeatedRules(property *openapi.Schema, rules protoreflect.Message) bool {
+	if count := getDynamicUint(rules, "min_items"); count != nil {
+		property.MinItems = count
+	}
+	if count := getDynamicUint(rules, "max_items"); count != nil {
+		property.MaxItems = count
+	}
+	if value, ok := getDynamicValue(rules, "unique"); ok && value.Bool() {
+		property.UniqueItems = true
+	}
+	if value, ok := getDynamicValue(rules, "non_empty"); ok && value.Bool() {
+		if property.MinItems == nil || *property.MinItems == 0 {
+			property.MinItems = newUint(1)
+		}
+		return true
+	}
+	return false
+}
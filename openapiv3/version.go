@@ -0,0 +1,146 @@
+package openapiv3
+
+import (
+	"sort"
+
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/openapi"
+)
+
+// OpenAPI versions of the openapi_version option
+const (
+	// OpenAPIVersion30 writes OpenAPI 3.0 documents
+	OpenAPIVersion30 = "3.0"
+	// OpenAPIVersion31 writes OpenAPI 3.1 documents using JSON Schema 2020-12 semantics
+	OpenAPIVersion31 = "3.1"
+)
+
+// applyOpenAPIVersion rewrites the schemas of a document, which are built with
+// OpenAPI 3.0 keywords, for the requested version.
+// Migration guide:
+// https://www.openapis.org/blog/2021/02/16/migrating-from-openapi-3-0-to-3-1-0
+func applyOpenAPIVersion(doc *openapi.Document, version string) {
+	if version != OpenAPIVersion31 {
+		walkDocumentSchemas(doc, wrapRefSiblings)
+		return
+	}
+	doc.OpenAPI = "3.1.0"
+	walkDocumentSchemas(doc, convertSchemaTo31)
+}
+
+// wrapRefSiblings moves a $ref with sibling keywords into allOf, since OpenAPI
+// 3.0 ignores everything next to a $ref
+func wrapRefSiblings(schema *openapi.Schema) {
+	if !schema.HasSiblings() {
+		return
+	}
+	ref := &openapi.Schema{Ref: schema.Ref}
+	schema.Ref = ""
+	schema.AllOf = append([]*openapi.Schema{ref}, schema.AllOf...)
+}
+
+// convertSchemaTo31 replaces OpenAPI 3.0 keywords with their JSON Schema 2020-12 equivalents
+func convertSchemaTo31(schema *openapi.Schema) {
+	// nullable is replaced by a type array
+	if schema.Nullable {
+		schema.Nullable = false
+		if len(schema.Type) > 0 {
+			schema.Type = append(schema.Type, "null")
+		} else if schema.Ref == "" {
+			schema.Type = openapi.Types{"null"}
+		}
+	}
+
+	// example is replaced by examples
+	if schema.Example != nil {
+		schema.Examples = []any{schema.Example}
+		schema.Example = nil
+	}
+
+	// enums with a single value are constants
+	if len(schema.Enum) == 1 {
+		schema.Const = schema.Enum[0]
+		schema.Enum = nil
+	}
+
+	// base64 encoded bytes use contentEncoding instead of the byte format
+	if schema.Format == "byte" {
+		schema.Format = ""
+		schema.ContentEncoding = "base64"
+	}
+
+	// exclusiveMinimum and exclusiveMaximum are numbers instead of booleans
+	if schema.ExclusiveMinimum {
+		schema.ExclusiveMinimum = false
+		schema.ExclusiveMinimumValue, schema.Minimum = schema.Minimum, nil
+	}
+	if schema.ExclusiveMaximum {
+		schema.ExclusiveMaximum = false
+		schema.ExclusiveMaximumValue, schema.Maximum = schema.Maximum, nil
+	}
+}
+
+// walkDocumentSchemas calls fn for every schema object of the document,
+// examples are never visited since they may hold arbitrary values
+func walkDocumentSchemas(doc *openapi.Document, fn func(schema *openapi.Schema)) {
+	if doc.Components != nil {
+		for _, name := range sortedKeys(doc.Components.Schemas) {
+			walkSchema(doc.Components.Schemas[name], fn)
+		}
+	}
+
+	for _, path := range sortedKeys(doc.Paths) {
+		pathItem := doc.Paths[path]
+		for _, method := range pathItem.Methods() {
+			operation := pathItem.Operation(method)
+			for _, parameter := range operation.Parameters {
+				walkSchema(parameter.Schema, fn)
+			}
+			if operation.RequestBody != nil {
+				walkContentSchemas(operation.RequestBody.Content, fn)
+			}
+			for _, code := range sortedKeys(operation.Responses) {
+				walkContentSchemas(operation.Responses[code].Content, fn)
+			}
+		}
+	}
+}
+
+// walkContentSchemas visits the schemas of the media types of a request body or response
+func walkContentSchemas(content map[string]*openapi.MediaType, fn func(schema *openapi.Schema)) {
+	for _, mediaType := range sortedKeys(content) {
+		walkSchema(content[mediaType].Schema, fn)
+	}
+}
+
+// walkSchema calls fn for the schema and then for all of its subschemas
+func walkSchema(schema *openapi.Schema, fn func(schema *openapi.Schema)) {
+	if schema == nil {
+		return
+	}
+	fn(schema)
+
+	for _, name := range sortedKeys(schema.Properties) {
+		walkSchema(schema.Properties[name], fn)
+	}
+	walkSchema(schema.Items, fn)
+	if schema.AdditionalProperties != nil {
+		walkSchema(schema.AdditionalProperties.Schema, fn)
+	}
+	walkSchema(schema.Not, fn)
+	for _, subschemas := range [][]*openapi.Schema{schema.AllOf, schema.OneOf, schema.AnyOf} {
+		for _, subschema := range subschemas {
+			walkSchema(subschema, fn)
+		}
+	}
+}
+
+// sortedKeys returns the keys of the map in order, so that documents are
+// walked and reported on in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
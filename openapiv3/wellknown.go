@@ -0,0 +1,169 @@
+package openapiv3
+
+import (
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/protoc-gen/protoc-gen-openapiv3/pkg/openapi"
+)
+
+// defaultTimestamp is the example used for google.protobuf.Timestamp fields
+// without an explicit example option.
+const defaultTimestamp = 1741589979
+
+// wellKnownType describes how a google.protobuf well-known type is represented
+// in the canonical proto3 JSON mapping.
+// JSON mapping specification:
+// https://protobuf.dev/programming-guides/json/
+type wellKnownType struct {
+	property func() *openapi.Schema
+	example  func(field *protogen.Field) any
+}
+
+var wellKnownTypes = map[protoreflect.FullName]wellKnownType{
+	"google.protobuf.Timestamp": {
+		property: func() *openapi.Schema {
+			return &openapi.Schema{Type: openapi.Types{"string"}, Format: "date-time"}
+		},
+		example: timestampExample,
+	},
+	"google.protobuf.Duration": {
+		property: func() *openapi.Schema {
+			return &openapi.Schema{Type: openapi.Types{"string"}, Pattern: `^-?[0-9]+(\.[0-9]{1,9})?s$`}
+		},
+		example: func(field *protogen.Field) any { return wellKnownExample(field, "1.5s") },
+	},
+	"google.protobuf.DoubleValue": wrapperType("number", "double", 0.0),
+	"google.protobuf.FloatValue":  wrapperType("number", "float", 0.0),
+	// 64-bit integers are encoded as JSON strings by protojson
+	"google.protobuf.Int64Value":  wrapperType("string", "int64", "0"),
+	"google.protobuf.UInt64Value": wrapperType("string", "uint64", "0"),
+	"google.protobuf.Int32Value":  wrapperType("integer", "int32", 0),
+	"google.protobuf.UInt32Value": wrapperType("integer", "uint32", 0),
+	"google.protobuf.BoolValue":   wrapperType("boolean", "", true),
+	"google.protobuf.StringValue": wrapperType("string", "", ""),
+	"google.protobuf.BytesValue":  wrapperType("string", "byte", ""),
+	"google.protobuf.Struct": {
+		property: func() *openapi.Schema {
+			return &openapi.Schema{
+				Type:                 openapi.Types{"object"},
+				AdditionalProperties: &openapi.AdditionalProperties{Allowed: true},
+			}
+		},
+		example: func(*protogen.Field) any { return map[string]any{"key": "value"} },
+	},
+	"google.protobuf.Value": {
+		// Value can hold any JSON value, an empty schema accepts all of them
+		property: func() *openapi.Schema { return &openapi.Schema{} },
+		example:  func(*protogen.Field) any { return "value" },
+	},
+	"google.protobuf.ListValue": {
+		property: func() *openapi.Schema {
+			return &openapi.Schema{Type: openapi.Types{"array"}, Items: &openapi.Schema{}}
+		},
+		example: func(*protogen.Field) any { return []any{"value"} },
+	},
+	"google.protobuf.FieldMask": {
+		// Paths are joined with commas and converted to lowerCamelCase
+		property: func() *openapi.Schema {
+			return &openapi.Schema{Type: openapi.Types{"string"}, Format: "field-mask"}
+		},
+		example: func(field *protogen.Field) any { return wellKnownExample(field, "title,description") },
+	},
+	"google.protobuf.Empty": {
+		property: func() *openapi.Schema { return &openapi.Schema{Type: openapi.Types{"object"}} },
+		example:  func(*protogen.Field) any { return map[string]any{} },
+	},
+	"google.protobuf.Any": {
+		property: func() *openapi.Schema {
+			return &openapi.Schema{
+				Type: openapi.Types{"object"},
+				Properties: map[string]*openapi.Schema{
+					"@type": {Type: openapi.Types{"string"}},
+				},
+				Required:             []string{"@type"},
+				AdditionalProperties: &openapi.AdditionalProperties{Allowed: true},
+			}
+		},
+		example: func(*protogen.Field) any {
+			return map[string]any{
+				"@type": "type.googleapis.com/google.protobuf.Duration",
+				"value": "1.5s",
+			}
+		},
+	},
+}
+
+// wrapperType maps a google.protobuf wrapper message to its nullable primitive
+func wrapperType[T openAPITypes](typ, format string, defValue T) wellKnownType {
+	return wellKnownType{
+		property: func() *openapi.Schema {
+			return &openapi.Schema{Type: openapi.Types{typ}, Format: format, Nullable: true}
+		},
+		example: func(field *protogen.Field) any { return wellKnownExample(field, defValue) },
+	}
+}
+
+// wellKnownExample is getExample for fields that may be absent, e.g. when the
+// well-known type is used as a method input or output
+func wellKnownExample[T openAPITypes](field *protogen.Field, defValue T) T {
+	if field == nil {
+		return defValue
+	}
+	return getExample(field, defValue)
+}
+
+// timestampExample returns an RFC 3339 example, unix seconds given through the
+// example option are converted for backward compatibility
+func timestampExample(field *protogen.Field) any {
+	val := wellKnownExample(field, "")
+	if val == "" {
+		return time.Unix(defaultTimestamp, 0).UTC().Format(time.RFC3339)
+	}
+	if sec, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC().Format(time.RFC3339)
+	}
+	return val
+}
+
+// isValidWellKnownExample reports whether an example option value can be used
+// for a field of the well-known type, the other types use the value as is
+func isValidWellKnownExample(name protoreflect.FullName, val string) bool {
+	switch name {
+	case "google.protobuf.Timestamp":
+		if _, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return true
+		}
+		_, err := time.Parse(time.RFC3339, val)
+		return err == nil
+	case "google.protobuf.Int32Value", "google.protobuf.UInt32Value":
+		_, err := strconv.Atoi(val)
+		return err == nil
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue":
+		_, err := strconv.ParseFloat(val, 64)
+		return err == nil
+	case "google.protobuf.BoolValue":
+		_, err := strconv.ParseBool(val)
+		return err == nil
+	}
+	return true
+}
+
+// isWellKnownType reports whether the message has a canonical JSON mapping
+func isWellKnownType(message *protogen.Message) bool {
+	if message == nil {
+		return false
+	}
+	_, ok := wellKnownTypes[message.Desc.FullName()]
+	return ok
+}
+
+// getWellKnownPropertyAndExample returns the schema and example of a well-known
+// type, field is optional and only used to look up the example option
+func getWellKnownPropertyAndExample(message *protogen.Message, field *protogen.Field) (*openapi.Schema, any) {
+	wkt := wellKnownTypes[message.Desc.FullName()]
+	return wkt.property(), wkt.example(field)
+}
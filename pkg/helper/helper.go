@@ -2,6 +2,9 @@ package helper
 
 import (
 	"fmt"
+	"slices"
+	"strings"
+
 	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/proto"
@@ -20,43 +23,100 @@ func GetEnumValues(enum *protogen.Enum) []string {
 	return values
 }
 
-func GetHttpMethodAndPath(method *protogen.Method) (methodPath string, httpMethod string, additionalBindings []string) {
+// HttpBinding is a single HTTP route of a method
+type HttpBinding struct {
+	// Method is the lower case HTTP method
+	Method string
+	Path   string
+	// Body is the request body selector: "*", a top-level field name or empty for no body
+	Body string
+	// ResponseBody names the output field returned instead of the whole message
+	ResponseBody string
+}
+
+// customHttpMethods are the methods of an OpenAPI path item that can be used as
+// custom HttpRule kinds, the others have their own HttpRule pattern.
+// Path item specification:
+// https://swagger.io/specification/v3/#path-item-object
+var customHttpMethods = map[string]struct{}{
+	"get":     {},
+	"put":     {},
+	"post":    {},
+	"delete":  {},
+	"options": {},
+	"head":    {},
+	"patch":   {},
+	"trace":   {},
+}
+
+// GetHttpBindings returns the google.api.http rule of a method followed by its additional bindings
+func GetHttpBindings(method *protogen.Method) ([]HttpBinding, error) {
 	httpRule := proto.GetExtension(method.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
-	if httpRule != nil {
-		switch pattern := httpRule.Pattern.(type) {
-		case *annotations.HttpRule_Post:
-			methodPath = pattern.Post
-			httpMethod = "post"
-		case *annotations.HttpRule_Get:
-			methodPath = pattern.Get
-			httpMethod = "get"
-		case *annotations.HttpRule_Put:
-			methodPath = pattern.Put
-			httpMethod = "put"
-		case *annotations.HttpRule_Delete:
-			methodPath = pattern.Delete
-			httpMethod = "delete"
-		case *annotations.HttpRule_Patch:
-			methodPath = pattern.Patch
-			httpMethod = "patch"
-		}
+	if httpRule == nil {
+		return nil, nil
+	}
 
-		for _, binding := range httpRule.AdditionalBindings {
-			switch pattern := binding.Pattern.(type) {
-			case *annotations.HttpRule_Post:
-				additionalBindings = append(additionalBindings, pattern.Post)
-			case *annotations.HttpRule_Get:
-				additionalBindings = append(additionalBindings, pattern.Get)
-			case *annotations.HttpRule_Put:
-				additionalBindings = append(additionalBindings, pattern.Put)
-			case *annotations.HttpRule_Delete:
-				additionalBindings = append(additionalBindings, pattern.Delete)
-			case *annotations.HttpRule_Patch:
-				additionalBindings = append(additionalBindings, pattern.Patch)
+	var bindings []HttpBinding
+	for _, rule := range append([]*annotations.HttpRule{httpRule}, httpRule.AdditionalBindings...) {
+		methodPath, httpMethod := GetHttpRuleMethodAndPath(rule)
+		if custom := rule.GetCustom(); custom != nil {
+			if _, ok := customHttpMethods[httpMethod]; !ok {
+				return nil, fmt.Errorf("%s: custom HTTP method %q can't be represented in OpenAPI", method.Desc.FullName(), custom.GetKind())
 			}
 		}
+		if httpMethod == "" || methodPath == "" {
+			return nil, fmt.Errorf("%s: google.api.http rule has no HTTP method or path", method.Desc.FullName())
+		}
+		bindings = append(bindings, HttpBinding{
+			Method:       httpMethod,
+			Path:         methodPath,
+			Body:         rule.GetBody(),
+			ResponseBody: rule.GetResponseBody(),
+		})
+	}
+	return bindings, nil
+}
+
+// GetHttpMethodAndPath returns the path and lower case HTTP method of the
+// google.api.http rule of a method and the paths of its additional bindings.
+//
+// Deprecated: use GetHttpBindings, which also returns the HTTP methods and body
+// selectors of the additional bindings.
+func GetHttpMethodAndPath(method *protogen.Method) (methodPath string, httpMethod string, additionalBindings []string) {
+	httpRule := proto.GetExtension(method.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
+	if httpRule == nil {
+		return
+	}
+	methodPath, httpMethod = GetHttpRuleMethodAndPath(httpRule)
+	for _, binding := range httpRule.AdditionalBindings {
+		path, _ := GetHttpRuleMethodAndPath(binding)
+		additionalBindings = append(additionalBindings, path)
 	}
+	return
+}
 
+// GetHttpRuleMethodAndPath returns the path and lower case HTTP method of a rule
+func GetHttpRuleMethodAndPath(rule *annotations.HttpRule) (methodPath string, httpMethod string) {
+	switch pattern := rule.Pattern.(type) {
+	case *annotations.HttpRule_Post:
+		methodPath = pattern.Post
+		httpMethod = "post"
+	case *annotations.HttpRule_Get:
+		methodPath = pattern.Get
+		httpMethod = "get"
+	case *annotations.HttpRule_Put:
+		methodPath = pattern.Put
+		httpMethod = "put"
+	case *annotations.HttpRule_Delete:
+		methodPath = pattern.Delete
+		httpMethod = "delete"
+	case *annotations.HttpRule_Patch:
+		methodPath = pattern.Patch
+		httpMethod = "patch"
+	case *annotations.HttpRule_Custom:
+		methodPath = pattern.Custom.GetPath()
+		httpMethod = strings.ToLower(pattern.Custom.GetKind())
+	}
 	return
 }
 
@@ -68,3 +128,31 @@ func GetFieldFromMessage(message *protogen.Message, fieldName string) *protogen.
 	}
 	return nil
 }
+
+// GetFieldPath resolves a dotted field path such as "trip.id" to the chain of
+// fields it traverses, it returns nil if a field doesn't exist or a field other
+// than the last one isn't a singular message
+func GetFieldPath(message *protogen.Message, fieldPath string) []*protogen.Field {
+	var fields []*protogen.Field
+	for _, name := range strings.Split(fieldPath, ".") {
+		if message == nil {
+			return nil
+		}
+		field := GetFieldFromMessage(message, name)
+		if field == nil {
+			return nil
+		}
+		fields = append(fields, field)
+		message = nil
+		if field.Message != nil && !field.Desc.IsList() && !field.Desc.IsMap() {
+			message = field.Message
+		}
+	}
+	return fields
+}
+
+// HasFieldBehavior reports whether the field is annotated with the google.api.field_behavior
+func HasFieldBehavior(field *protogen.Field, behavior annotations.FieldBehavior) bool {
+	behaviors, _ := proto.GetExtension(field.Desc.Options(), annotations.E_FieldBehavior).([]annotations.FieldBehavior)
+	return slices.Contains(behaviors, behavior)
+}
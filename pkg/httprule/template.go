@@ -0,0 +1,290 @@
+package httprule
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Template is a parsed google.api.http path template.
+// Path template syntax:
+// https://github.com/googleapis/googleapis/blob/master/google/api/http.proto
+//
+//	Template = "/" Segments [ Verb ] ;
+//	Segments = Segment { "/" Segment } ;
+//	Segment  = "*" | "**" | LITERAL | Variable ;
+//	Variable = "{" FieldPath [ "=" Segments ] "}" ;
+//	FieldPath = IDENT { "." IDENT } ;
+//	Verb     = ":" LITERAL ;
+//
+// "/" alone is the root path, "**" can only be the last segment and the legacy
+// query form /v1/trips?page={page} is accepted, its query is ignored since
+// query parameters are derived from the request fields.
+type Template struct {
+	Segments []Segment
+	// Verb is the custom verb without the leading colon, e.g. "cancel"
+	Verb string
+}
+
+// Segment is either a literal, a wildcard ("*" or "**") or a variable
+type Segment struct {
+	Literal  string
+	Variable *Variable
+}
+
+// Variable binds one or more path segments to a request field
+type Variable struct {
+	// FieldPath is the dotted field path, e.g. "trip.id"
+	FieldPath string
+	// Segments is the pattern matched by the variable, {id} is the same as {id=*}
+	Segments []string
+}
+
+// Parse parses a path template such as /v1/{name=projects/*/trips/*}:cancel
+func Parse(template string) (*Template, error) {
+	p := &parser{input: template}
+	t, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid path template %q: %w", template, err)
+	}
+	return t, nil
+}
+
+// Path returns the OpenAPI path, each variable is replaced by a single path
+// parameter called name(FieldPath), top level wildcards by the parameters
+// returned by Wildcards and the verb is kept as a suffix
+func (t *Template) Path(name func(fieldPath string) string) string {
+	var sb strings.Builder
+	wildcards := t.Wildcards()
+	for _, segment := range t.Segments {
+		sb.WriteString("/")
+		switch {
+		case segment.Variable != nil:
+			sb.WriteString("{" + name(segment.Variable.FieldPath) + "}")
+		case segment.isWildcard():
+			sb.WriteString("{" + wildcards[0].FieldPath + "}")
+			wildcards = wildcards[1:]
+		default:
+			sb.WriteString(segment.Literal)
+		}
+	}
+	if len(t.Segments) == 0 {
+		sb.WriteString("/")
+	}
+	if t.Verb != "" {
+		sb.WriteString(":" + t.Verb)
+	}
+	return sb.String()
+}
+
+// Variables returns the variables of the template in order
+func (t *Template) Variables() []*Variable {
+	var variables []*Variable
+	for _, segment := range t.Segments {
+		if segment.Variable != nil {
+			variables = append(variables, segment.Variable)
+		}
+	}
+	return variables
+}
+
+// Wildcards returns the top level wildcards, which match path segments without
+// binding them to a field, as variables named wildcard, wildcard2 and so on,
+// e.g. /v1/*/trips is /v1/{wildcard}/trips
+func (t *Template) Wildcards() []*Variable {
+	var wildcards []*Variable
+	for _, segment := range t.Segments {
+		if !segment.isWildcard() {
+			continue
+		}
+		name := "wildcard"
+		if len(wildcards) > 0 {
+			name = fmt.Sprintf("%s%d", name, len(wildcards)+1)
+		}
+		wildcards = append(wildcards, &Variable{FieldPath: name, Segments: []string{segment.Literal}})
+	}
+	return wildcards
+}
+
+func (s Segment) isWildcard() bool {
+	return s.Variable == nil && (s.Literal == "*" || s.Literal == "**")
+}
+
+// Pattern returns a regular expression matching the variable value, it's
+// empty for single segment variables which need no constraint
+func (v *Variable) Pattern() string {
+	if len(v.Segments) == 1 && v.Segments[0] == "*" {
+		return ""
+	}
+	parts := make([]string, 0, len(v.Segments))
+	for _, segment := range v.Segments {
+		switch segment {
+		case "*":
+			parts = append(parts, "[^/]+")
+		case "**":
+			parts = append(parts, ".+")
+		default:
+			parts = append(parts, regexp.QuoteMeta(segment))
+		}
+	}
+	return "^" + strings.Join(parts, "/") + "$"
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) parse() (*Template, error) {
+	p.input, _, _ = strings.Cut(p.input, "?")
+	if !p.consume('/') {
+		return nil, fmt.Errorf("must start with '/'")
+	}
+	t := &Template{}
+	if p.pos == len(p.input) {
+		return t, nil
+	}
+	segments, err := p.segments(true)
+	if err != nil {
+		return nil, err
+	}
+	t.Segments = segments
+	if err := checkDoubleWildcard(segments); err != nil {
+		return nil, err
+	}
+	if p.consume(':') {
+		t.Verb = p.literal()
+		if t.Verb == "" {
+			return nil, fmt.Errorf("empty verb at %d", p.pos)
+		}
+	}
+	if p.pos != len(p.input) {
+		return nil, fmt.Errorf("unexpected %q at %d", p.input[p.pos], p.pos)
+	}
+	return t, nil
+}
+
+// segments parses Segments, variables are only allowed at the top level
+func (p *parser) segments(allowVariables bool) ([]Segment, error) {
+	var segments []Segment
+	for {
+		segment, err := p.segment(allowVariables)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment)
+		if !p.consume('/') {
+			return segments, nil
+		}
+	}
+}
+
+// checkDoubleWildcard checks that "**" is the last segment of the path, either
+// at the top level or in the last variable
+func checkDoubleWildcard(segments []Segment) error {
+	var all []string
+	for _, segment := range segments {
+		if segment.Variable != nil {
+			all = append(all, segment.Variable.Segments...)
+		} else {
+			all = append(all, segment.Literal)
+		}
+	}
+	for i, segment := range all {
+		if segment == "**" && i != len(all)-1 {
+			return fmt.Errorf("'**' must be the last segment")
+		}
+	}
+	return nil
+}
+
+func (p *parser) segment(allowVariables bool) (Segment, error) {
+	switch {
+	case strings.HasPrefix(p.input[p.pos:], "**"):
+		p.pos += 2
+		return Segment{Literal: "**"}, nil
+	case p.consume('*'):
+		return Segment{Literal: "*"}, nil
+	case p.peek() == '{':
+		if !allowVariables {
+			return Segment{}, fmt.Errorf("nested variable at %d", p.pos)
+		}
+		variable, err := p.variable()
+		if err != nil {
+			return Segment{}, err
+		}
+		return Segment{Variable: variable}, nil
+	}
+	literal := p.literal()
+	if literal == "" {
+		return Segment{}, fmt.Errorf("empty segment at %d", p.pos)
+	}
+	return Segment{Literal: literal}, nil
+}
+
+func (p *parser) variable() (*Variable, error) {
+	p.consume('{')
+	start := p.pos
+	for p.pos < len(p.input) && strings.IndexByte("=}", p.input[p.pos]) == -1 {
+		p.pos++
+	}
+	fieldPath := p.input[start:p.pos]
+	for _, ident := range strings.Split(fieldPath, ".") {
+		if !isIdent(ident) {
+			return nil, fmt.Errorf("invalid field path %q at %d", fieldPath, start)
+		}
+	}
+
+	variable := &Variable{FieldPath: fieldPath, Segments: []string{"*"}}
+	if p.consume('=') {
+		segments, err := p.segments(false)
+		if err != nil {
+			return nil, err
+		}
+		variable.Segments = variable.Segments[:0]
+		for _, segment := range segments {
+			variable.Segments = append(variable.Segments, segment.Literal)
+		}
+	}
+	if !p.consume('}') {
+		return nil, fmt.Errorf("missing '}' at %d", p.pos)
+	}
+	return variable, nil
+}
+
+// literal reads up to the next character with a meaning in the grammar
+func (p *parser) literal() string {
+	start := p.pos
+	for p.pos < len(p.input) && strings.IndexByte("/{}=*:", p.input[p.pos]) == -1 {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *parser) peek() byte {
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *parser) consume(c byte) bool {
+	if p.peek() == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,168 @@
+package httprule
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		path     string
+		verb     string
+		// patterns maps the field path of each variable to its pattern
+		patterns map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "literal",
+			template: "/v1/trips",
+			path:     "/v1/trips",
+		},
+		{
+			name:     "root",
+			template: "/",
+			path:     "/",
+		},
+		{
+			name:     "variable",
+			template: "/v1/trips/{id}",
+			path:     "/v1/trips/{id}",
+			patterns: map[string]string{"id": ""},
+		},
+		{
+			name:     "variable with segments",
+			template: "/v1/{name=projects/*/trips/*}",
+			path:     "/v1/{name}",
+			patterns: map[string]string{"name": "^projects/[^/]+/trips/[^/]+$"},
+		},
+		{
+			name:     "variable with double wildcard",
+			template: "/v1/{parent=**}",
+			path:     "/v1/{parent}",
+			patterns: map[string]string{"parent": "^.+$"},
+		},
+		{
+			name:     "nested field path",
+			template: "/v1/trips/{a.b}",
+			path:     "/v1/trips/{a.b}",
+			patterns: map[string]string{"a.b": ""},
+		},
+		{
+			name:     "verb",
+			template: "/v1/{name=trips/*}:cancel",
+			path:     "/v1/{name}:cancel",
+			verb:     "cancel",
+			patterns: map[string]string{"name": "^trips/[^/]+$"},
+		},
+		{
+			name:     "top level wildcards",
+			template: "/v1/*/trips/**",
+			path:     "/v1/{wildcard}/trips/{wildcard2}",
+		},
+		{
+			name:     "legacy query",
+			template: "/api/v1/trips?page={page}&size={size}",
+			path:     "/api/v1/trips",
+		},
+		{
+			name:     "missing leading slash",
+			template: "v1/trips",
+			wantErr:  true,
+		},
+		{
+			name:     "empty segment",
+			template: "/v1//trips",
+			wantErr:  true,
+		},
+		{
+			name:     "double wildcard not last",
+			template: "/v1/**/trips",
+			wantErr:  true,
+		},
+		{
+			name:     "double wildcard in variable not last",
+			template: "/v1/{name=trips/**}/cancel",
+			wantErr:  true,
+		},
+		{
+			name:     "nested variable",
+			template: "/v1/{name={id}}",
+			wantErr:  true,
+		},
+		{
+			name:     "invalid field path",
+			template: "/v1/{trip..id}",
+			wantErr:  true,
+		},
+		{
+			name:     "missing closing brace",
+			template: "/v1/{id",
+			wantErr:  true,
+		},
+		{
+			name:     "empty verb",
+			template: "/v1/trips:",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := Parse(tt.template)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %+v, want an error", tt.template, tmpl)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.template, err)
+			}
+
+			path := tmpl.Path(func(fieldPath string) string { return fieldPath })
+			if path != tt.path {
+				t.Errorf("Path() = %q, want %q", path, tt.path)
+			}
+			if tmpl.Verb != tt.verb {
+				t.Errorf("Verb = %q, want %q", tmpl.Verb, tt.verb)
+			}
+			patterns := make(map[string]string)
+			for _, variable := range tmpl.Variables() {
+				patterns[variable.FieldPath] = variable.Pattern()
+			}
+			if len(patterns) == 0 {
+				patterns = nil
+			}
+			if !reflect.DeepEqual(patterns, tt.patterns) {
+				t.Errorf("variable patterns = %v, want %v", patterns, tt.patterns)
+			}
+		})
+	}
+}
+
+func TestTemplateWildcards(t *testing.T) {
+	tmpl, err := Parse("/v1/*/trips/**")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [][2]string
+	for _, wildcard := range tmpl.Wildcards() {
+		got = append(got, [2]string{wildcard.FieldPath, wildcard.Pattern()})
+	}
+	want := [][2]string{{"wildcard", ""}, {"wildcard2", "^.+$"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wildcards() = %v, want %v", got, want)
+	}
+}
+
+func TestTemplatePathNaming(t *testing.T) {
+	tmpl, err := Parse("/v1/{trip.trip_id}/days/{day_id}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := tmpl.Path(func(fieldPath string) string { return "x_" + fieldPath })
+	if want := "/v1/{x_trip.trip_id}/days/{x_day_id}"; path != want {
+		t.Errorf("Path() = %q, want %q", path, want)
+	}
+}
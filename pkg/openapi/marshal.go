@@ -0,0 +1,156 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// object is the ordered list of the fields of a model type, both YAML and JSON
+// keep that order so documents are laid out like the specification and their
+// output is stable
+type object []field
+
+type field struct {
+	key   string
+	value any
+}
+
+// add appends the field unless the value is empty, nil slices and maps are left
+// out but empty ones are kept since they can be meaningful, e.g. security: []
+func (o *object) add(key string, value any) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return
+	case reflect.String, reflect.Bool:
+		if v.IsZero() {
+			return
+		}
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+	}
+	*o = append(*o, field{key, sortMaps(value)})
+}
+
+// addAny appends the field unless the value is nil, it's used for example and
+// default values where zero values such as "" or 0 must be written
+func (o *object) addAny(key string, value any) {
+	if value != nil {
+		*o = append(*o, field{key, sortMaps(value)})
+	}
+}
+
+// sortMaps turns the maps with string keys found in the value into objects
+// sorted by key, YAML and JSON encoders sort map keys differently, e.g. yaml.v3
+// puts X_Body10 after X_Body2, so both get the same explicit order. Values with
+// their own marshaller are kept as they order their fields themselves.
+func sortMaps(value any) any {
+	if _, ok := value.(json.Marshaler); ok {
+		return value
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		o := make(object, 0, len(keys))
+		for _, key := range keys {
+			o = append(o, field{key, sortMaps(v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())).Interface())})
+		}
+		return o
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return value
+		}
+		values := make([]any, v.Len())
+		for i := range values {
+			values[i] = sortMaps(v.Index(i).Interface())
+		}
+		return values
+	}
+	return value
+}
+
+// addExtensions appends the specification extensions sorted by name
+func (o *object) addExtensions(extensions map[string]any) {
+	keys := make([]string, 0, len(extensions))
+	for key := range extensions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		o.addAny(key, extensions[key])
+	}
+}
+
+// has reports whether the object has a field with the key
+func (o object) has(key string) bool {
+	for _, f := range o {
+		if f.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (o object) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, f := range o {
+		var key, value yaml.Node
+		if err := key.Encode(f.key); err != nil {
+			return nil, err
+		}
+		if err := value.Encode(f.value); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &key, &value)
+	}
+	return node, nil
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := marshalJSON(f.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := marshalJSON(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshalJSON is json.Marshal without escaping HTML characters, which would
+// turn patterns like ^[a-z]+$ and descriptions into unreadable \u escapes
+func marshalJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,180 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestObjectAdd(t *testing.T) {
+	var o object
+	o.add("string", "")
+	o.add("bool", false)
+	o.add("pointer", (*uint64)(nil))
+	o.add("slice", []string(nil))
+	o.add("map", map[string]string(nil))
+	o.add("nil", nil)
+	o.add("emptySlice", []string{})
+	o.add("emptyMap", map[string]string{})
+	o.add("zeroPointer", newUint64(0))
+	o.addAny("example", "")
+	o.addAny("default", 0)
+	o.addAny("missing", nil)
+
+	want := []string{"emptySlice", "emptyMap", "zeroPointer", "example", "default"}
+	if len(o) != len(want) {
+		t.Fatalf("object has %d fields, want %v", len(o), want)
+	}
+	for i, key := range want {
+		if o[i].key != key {
+			t.Errorf("field %d = %q, want %q", i, o[i].key, key)
+		}
+	}
+}
+
+func TestObjectAddExtensions(t *testing.T) {
+	var o object
+	o.addExtensions(map[string]any{"x-b": 1, "x-a": "", "x-c": nil})
+	got, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"x-a":"","x-b":1}`; string(got) != want {
+		t.Errorf("MarshalJSON() = %s, want %s", got, want)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	minLength := uint64(1)
+	schema := &Schema{
+		Type:      Types{"string"},
+		Title:     "Name",
+		Pattern:   "^<[a-z]+>&$",
+		MinLength: &minLength,
+		Example:   "",
+	}
+
+	tests := []struct {
+		name  string
+		value interface {
+			json.Marshaler
+			yaml.Marshaler
+		}
+		json string
+		yaml string
+	}{
+		{
+			name:  "schema keeps the field order",
+			value: schema,
+			json:  `{"title":"Name","type":"string","pattern":"^<[a-z]+>&$","minLength":1,"example":""}`,
+			yaml:  "title: Name\ntype: string\npattern: ^<[a-z]+>&$\nminLength: 1\nexample: \"\"\n",
+		},
+		{
+			name:  "empty security is kept",
+			value: &Operation{OperationID: "S_Get", Security: []SecurityRequirement{}},
+			json:  `{"operationId":"S_Get","security":[]}`,
+			yaml:  "operationId: S_Get\nsecurity: []\n",
+		},
+		{
+			name: "path item methods in order",
+			value: &PathItem{
+				Post: &Operation{OperationID: "S_Create"},
+				Get:  &Operation{OperationID: "S_Get"},
+			},
+			json: `{"get":{"operationId":"S_Get"},"post":{"operationId":"S_Create"}}`,
+			yaml: "get:\n    operationId: S_Get\npost:\n    operationId: S_Create\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// HTML characters aren't escaped, json.Marshal would escape them again
+			got, err := tt.value.MarshalJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.json {
+				t.Errorf("MarshalJSON() = %s, want %s", got, tt.json)
+			}
+
+			got, err = yaml.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.yaml {
+				t.Errorf("MarshalYAML() = %q, want %q", got, tt.yaml)
+			}
+		})
+	}
+}
+
+func newUint64(v uint64) *uint64 {
+	return &v
+}
+
+func TestMarshalKeyOrder(t *testing.T) {
+	doc := &Document{
+		OpenAPI: "3.0.0",
+		Paths: map[string]*PathItem{
+			"/api/v1/trips/{id}":   {Get: &Operation{OperationID: "GetTrip"}},
+			"/api/v1/trips/search": {Post: &Operation{OperationID: "SearchTrips"}},
+			"/api/v1/trips":        {Get: &Operation{OperationID: "ListTrips"}},
+		},
+		Components: &Components{
+			Schemas: map[string]*Schema{
+				"X_Body2":  {Type: Types{"object"}},
+				"X_Body10": {Type: Types{"object"}},
+				"X_Body":   {Type: Types{"object"}},
+				"x":        {Example: map[string]any{"b10": 1, "b2": []any{map[string]any{"Z": 1, "a": 2}}}},
+			},
+		},
+		Security: []SecurityRequirement{{"b": {}, "A": {}}},
+	}
+
+	b, err := doc.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// JSON is YAML, decoding it into a node keeps its key order
+	var jsonNode yaml.Node
+	if err := yaml.Unmarshal(b, &jsonNode); err != nil {
+		t.Fatal(err)
+	}
+	b, err = yaml.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var yamlNode yaml.Node
+	if err := yaml.Unmarshal(b, &yamlNode); err != nil {
+		t.Fatal(err)
+	}
+
+	jsonKeys, yamlKeys := getKeys(&jsonNode, nil), getKeys(&yamlNode, nil)
+	if !reflect.DeepEqual(jsonKeys, yamlKeys) {
+		t.Errorf("JSON keys %v differ from YAML keys %v", jsonKeys, yamlKeys)
+	}
+	want := []string{"/api/v1/trips", "/api/v1/trips/search", "/api/v1/trips/{id}"}
+	var paths []string
+	for _, key := range yamlKeys {
+		if strings.HasPrefix(key, "/") {
+			paths = append(paths, key)
+		}
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+}
+
+// getKeys returns the mapping keys of a YAML node depth first
+func getKeys(node *yaml.Node, keys []string) []string {
+	for i, child := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			keys = append(keys, child.Value)
+			continue
+		}
+		keys = getKeys(child, keys)
+	}
+	return keys
+}
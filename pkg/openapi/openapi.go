@@ -0,0 +1,314 @@
+// Package openapi is an object model of OpenAPI 3 and Swagger 2.0 documents.
+// The types only hold what the generator writes, zero values are left out of
+// the output and fields are written in the order of the specification.
+// OpenAPI specification:
+// https://spec.openapis.org/oas/v3.0.3
+package openapi
+
+// Document is the root object of an OpenAPI document
+type Document struct {
+	OpenAPI    string
+	Info       *Info
+	Servers    []*Server
+	Paths      map[string]*PathItem
+	Components *Components
+	Security   []SecurityRequirement
+	Tags       []*Tag
+	Extensions map[string]any
+}
+
+// Info is the metadata of the API
+type Info struct {
+	Title       string
+	Description string
+	Version     string
+}
+
+// Server is a server hosting the API
+type Server struct {
+	URL         string
+	Description string
+}
+
+// Tag groups operations, e.g. the operations of a service
+type Tag struct {
+	Name        string
+	Description string
+}
+
+// Components holds the reusable objects of the document
+type Components struct {
+	Schemas         map[string]*Schema
+	SecuritySchemes map[string]*SecurityScheme
+}
+
+// SecurityScheme is a security scheme of the API
+type SecurityScheme struct {
+	Type         string
+	Description  string
+	Name         string
+	In           string
+	Scheme       string
+	BearerFormat string
+}
+
+// SecurityRequirement maps security scheme names to the required scopes, an
+// empty list of requirements removes the security of an operation
+type SecurityRequirement map[string][]string
+
+// PathItem holds the operations of a path
+type PathItem struct {
+	Get     *Operation
+	Put     *Operation
+	Post    *Operation
+	Delete  *Operation
+	Options *Operation
+	Head    *Operation
+	Patch   *Operation
+	Trace   *Operation
+}
+
+// Operation is an API operation on a path
+type Operation struct {
+	Tags        []string
+	Summary     string
+	Description string
+	OperationID string
+	Parameters  []*Parameter
+	RequestBody *RequestBody
+	Responses   map[string]*Response
+	Deprecated  bool
+	// Security overrides the document security when it isn't nil
+	Security   []SecurityRequirement
+	Extensions map[string]any
+}
+
+// Parameter is a path, query, header or cookie parameter of an operation
+type Parameter struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+	Deprecated  bool
+	Style       string
+	Explode     bool
+	Schema      *Schema
+	Example     any
+	Extensions  map[string]any
+}
+
+// RequestBody is the request body of an operation
+type RequestBody struct {
+	Description string
+	Content     map[string]*MediaType
+	Required    bool
+}
+
+// Response is a response of an operation
+type Response struct {
+	Description string
+	Content     map[string]*MediaType
+}
+
+// MediaType is the content of a request body or response for a media type
+type MediaType struct {
+	Schema  *Schema
+	Example any
+}
+
+// operationMethods are the HTTP methods of the operations of a path item
+var operationMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// operation returns the field of the path item holding the operation of the lowercase method
+func (p *PathItem) operation(method string) **Operation {
+	switch method {
+	case "get":
+		return &p.Get
+	case "put":
+		return &p.Put
+	case "post":
+		return &p.Post
+	case "delete":
+		return &p.Delete
+	case "options":
+		return &p.Options
+	case "head":
+		return &p.Head
+	case "patch":
+		return &p.Patch
+	case "trace":
+		return &p.Trace
+	}
+	return nil
+}
+
+// Operation returns the operation of the lowercase HTTP method, or nil
+func (p *PathItem) Operation(method string) *Operation {
+	if op := p.operation(method); op != nil {
+		return *op
+	}
+	return nil
+}
+
+// SetOperation sets the operation of the lowercase HTTP method, it reports
+// false for methods OpenAPI can't represent
+func (p *PathItem) SetOperation(method string, operation *Operation) bool {
+	op := p.operation(method)
+	if op == nil {
+		return false
+	}
+	*op = operation
+	return true
+}
+
+// Methods returns the HTTP methods of the operations of the path item in order
+func (p *PathItem) Methods() []string {
+	var methods []string
+	for _, method := range operationMethods {
+		if p.Operation(method) != nil {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+func (d *Document) object() object {
+	var o object
+	o.add("openapi", d.OpenAPI)
+	o.add("info", d.Info)
+	o.add("servers", d.Servers)
+	o.add("paths", d.Paths)
+	o.add("components", d.Components)
+	o.add("security", d.Security)
+	o.add("tags", d.Tags)
+	o.addExtensions(d.Extensions)
+	return o
+}
+
+func (i *Info) object() object {
+	var o object
+	o.add("title", i.Title)
+	o.add("description", i.Description)
+	o.add("version", i.Version)
+	return o
+}
+
+func (s *Server) object() object {
+	var o object
+	o.add("url", s.URL)
+	o.add("description", s.Description)
+	return o
+}
+
+func (t *Tag) object() object {
+	var o object
+	o.add("name", t.Name)
+	o.add("description", t.Description)
+	return o
+}
+
+func (c *Components) object() object {
+	var o object
+	o.add("schemas", c.Schemas)
+	o.add("securitySchemes", c.SecuritySchemes)
+	return o
+}
+
+func (s *SecurityScheme) object() object {
+	var o object
+	o.add("type", s.Type)
+	o.add("description", s.Description)
+	o.add("name", s.Name)
+	o.add("in", s.In)
+	o.add("scheme", s.Scheme)
+	o.add("bearerFormat", s.BearerFormat)
+	return o
+}
+
+func (p *PathItem) object() object {
+	var o object
+	for _, method := range p.Methods() {
+		o.add(method, p.Operation(method))
+	}
+	return o
+}
+
+func (op *Operation) object() object {
+	var o object
+	o.add("tags", op.Tags)
+	o.add("summary", op.Summary)
+	o.add("description", op.Description)
+	o.add("operationId", op.OperationID)
+	o.add("parameters", op.Parameters)
+	o.add("requestBody", op.RequestBody)
+	o.add("responses", op.Responses)
+	o.add("deprecated", op.Deprecated)
+	o.add("security", op.Security)
+	o.addExtensions(op.Extensions)
+	return o
+}
+
+func (p *Parameter) object() object {
+	var o object
+	o.add("name", p.Name)
+	o.add("in", p.In)
+	o.add("description", p.Description)
+	o.add("required", p.Required)
+	o.add("deprecated", p.Deprecated)
+	o.add("style", p.Style)
+	o.add("explode", p.Explode)
+	o.add("schema", p.Schema)
+	o.addAny("example", p.Example)
+	o.addExtensions(p.Extensions)
+	return o
+}
+
+func (r *RequestBody) object() object {
+	var o object
+	o.add("description", r.Description)
+	o.add("content", r.Content)
+	o.add("required", r.Required)
+	return o
+}
+
+func (r *Response) object() object {
+	var o object
+	// description is required even when it's empty
+	o.addAny("description", r.Description)
+	o.add("content", r.Content)
+	return o
+}
+
+func (m *MediaType) object() object {
+	var o object
+	o.add("schema", m.Schema)
+	o.addAny("example", m.Example)
+	return o
+}
+
+// The model types are marshalled through their ordered fields
+
+func (d *Document) MarshalYAML() (any, error)          { return d.object(), nil }
+func (d *Document) MarshalJSON() ([]byte, error)       { return d.object().MarshalJSON() }
+func (i *Info) MarshalYAML() (any, error)              { return i.object(), nil }
+func (i *Info) MarshalJSON() ([]byte, error)           { return i.object().MarshalJSON() }
+func (s *Server) MarshalYAML() (any, error)            { return s.object(), nil }
+func (s *Server) MarshalJSON() ([]byte, error)         { return s.object().MarshalJSON() }
+func (t *Tag) MarshalYAML() (any, error)               { return t.object(), nil }
+func (t *Tag) MarshalJSON() ([]byte, error)            { return t.object().MarshalJSON() }
+func (c *Components) MarshalYAML() (any, error)        { return c.object(), nil }
+func (c *Components) MarshalJSON() ([]byte, error)     { return c.object().MarshalJSON() }
+func (s *SecurityScheme) MarshalYAML() (any, error)    { return s.object(), nil }
+func (s *SecurityScheme) MarshalJSON() ([]byte, error) { return s.object().MarshalJSON() }
+func (p *PathItem) MarshalYAML() (any, error)          { return p.object(), nil }
+func (p *PathItem) MarshalJSON() ([]byte, error)       { return p.object().MarshalJSON() }
+func (op *Operation) MarshalYAML() (any, error)        { return op.object(), nil }
+func (op *Operation) MarshalJSON() ([]byte, error)     { return op.object().MarshalJSON() }
+func (p *Parameter) MarshalYAML() (any, error)         { return p.object(), nil }
+func (p *Parameter) MarshalJSON() ([]byte, error)      { return p.object().MarshalJSON() }
+func (r *RequestBody) MarshalYAML() (any, error)       { return r.object(), nil }
+func (r *RequestBody) MarshalJSON() ([]byte, error)    { return r.object().MarshalJSON() }
+func (r *Response) MarshalYAML() (any, error)          { return r.object(), nil }
+func (r *Response) MarshalJSON() ([]byte, error)       { return r.object().MarshalJSON() }
+func (m *MediaType) MarshalYAML() (any, error)         { return m.object(), nil }
+func (m *MediaType) MarshalJSON() ([]byte, error)      { return m.object().MarshalJSON() }
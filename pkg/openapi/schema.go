@@ -0,0 +1,168 @@
+package openapi
+
+import "reflect"
+
+// Schema is a schema object, it has the OpenAPI 3.0 keywords and the JSON
+// Schema 2020-12 ones used by OpenAPI 3.1.
+// Schema specification:
+// https://spec.openapis.org/oas/v3.0.3#schema-object
+type Schema struct {
+	Ref         string
+	Title       string
+	Description string
+	Type        Types
+	Format      string
+	Enum        []any
+	Const       any
+	Default     any
+	Pattern     string
+
+	MinLength   *uint64
+	MaxLength   *uint64
+	MinItems    *uint64
+	MaxItems    *uint64
+	UniqueItems bool
+	// MinProperties and MaxProperties bound the number of entries of a map
+	MinProperties *uint64
+	MaxProperties *uint64
+	Minimum       *float64
+	Maximum       *float64
+	// ExclusiveMinimum and ExclusiveMaximum make Minimum and Maximum exclusive,
+	// which is how OpenAPI 3.0 and Swagger 2.0 express exclusive bounds
+	ExclusiveMinimum bool
+	ExclusiveMaximum bool
+	// ExclusiveMinimumValue and ExclusiveMaximumValue are the exclusive bounds of OpenAPI 3.1
+	ExclusiveMinimumValue *float64
+	ExclusiveMaximumValue *float64
+
+	Items                *Schema
+	Properties           map[string]*Schema
+	AdditionalProperties *AdditionalProperties
+	Required             []string
+
+	AllOf         []*Schema
+	OneOf         []*Schema
+	AnyOf         []*Schema
+	Not           *Schema
+	Discriminator *Discriminator
+
+	Nullable        bool
+	ReadOnly        bool
+	WriteOnly       bool
+	Deprecated      bool
+	ContentEncoding string
+
+	Example    any
+	Examples   []any
+	Extensions map[string]any
+}
+
+// Types is the type of a schema, it has several entries in OpenAPI 3.1 where
+// nullable schemas add "null", e.g. ["string", "null"]
+type Types []string
+
+// Is reports whether the schema has the single type typ
+func (t Types) Is(typ string) bool {
+	return len(t) == 1 && t[0] == typ
+}
+
+// AdditionalProperties is either a schema for the values of an object or true
+// when any value is allowed
+type AdditionalProperties struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+// Discriminator tells which schema of a oneOf applies from a property value
+type Discriminator struct {
+	PropertyName string
+	Mapping      map[string]string
+}
+
+// NewRef returns a schema referencing a component schema
+func NewRef(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// HasSiblings reports whether a $ref schema has other keywords next to the $ref
+func (s *Schema) HasSiblings() bool {
+	sibling := *s
+	sibling.Ref = ""
+	return s.Ref != "" && !reflect.ValueOf(sibling).IsZero()
+}
+
+func (s *Schema) object() object {
+	var o object
+	o.add("$ref", s.Ref)
+	o.add("title", s.Title)
+	o.add("description", s.Description)
+	o.add("type", s.Type)
+	o.add("format", s.Format)
+	o.add("enum", s.Enum)
+	o.addAny("const", s.Const)
+	o.addAny("default", s.Default)
+	o.add("pattern", s.Pattern)
+	o.add("minLength", s.MinLength)
+	o.add("maxLength", s.MaxLength)
+	o.add("minItems", s.MinItems)
+	o.add("maxItems", s.MaxItems)
+	o.add("uniqueItems", s.UniqueItems)
+	o.add("minProperties", s.MinProperties)
+	o.add("maxProperties", s.MaxProperties)
+	o.add("minimum", s.Minimum)
+	o.add("maximum", s.Maximum)
+	o.add("exclusiveMinimum", s.ExclusiveMinimum)
+	o.add("exclusiveMaximum", s.ExclusiveMaximum)
+	o.add("exclusiveMinimum", s.ExclusiveMinimumValue)
+	o.add("exclusiveMaximum", s.ExclusiveMaximumValue)
+	o.add("items", s.Items)
+	o.add("properties", s.Properties)
+	o.add("additionalProperties", s.AdditionalProperties)
+	o.add("required", s.Required)
+	o.add("allOf", s.AllOf)
+	o.add("oneOf", s.OneOf)
+	o.add("anyOf", s.AnyOf)
+	o.add("not", s.Not)
+	o.add("discriminator", s.Discriminator)
+	o.add("nullable", s.Nullable)
+	o.add("readOnly", s.ReadOnly)
+	o.add("writeOnly", s.WriteOnly)
+	o.add("deprecated", s.Deprecated)
+	o.add("contentEncoding", s.ContentEncoding)
+	o.addAny("example", s.Example)
+	o.add("examples", s.Examples)
+	o.addExtensions(s.Extensions)
+	return o
+}
+
+// value returns a single type as a string and several types as a list
+func (t Types) value() any {
+	if len(t) == 1 {
+		return t[0]
+	}
+	return []string(t)
+}
+
+// value returns true or the schema of the additional properties
+func (a *AdditionalProperties) value() any {
+	if a.Schema != nil {
+		return a.Schema
+	}
+	return a.Allowed
+}
+
+func (d *Discriminator) object() object {
+	var o object
+	o.add("propertyName", d.PropertyName)
+	o.add("mapping", d.Mapping)
+	return o
+}
+
+func (s *Schema) MarshalYAML() (any, error)                  { return s.object(), nil }
+func (s *Schema) MarshalJSON() ([]byte, error)               { return s.object().MarshalJSON() }
+func (t Types) MarshalYAML() (any, error)                    { return t.value(), nil }
+func (t Types) MarshalJSON() ([]byte, error)                 { return marshalJSON(t.value()) }
+func (a *AdditionalProperties) MarshalYAML() (any, error)    { return a.value(), nil }
+func (a *AdditionalProperties) MarshalJSON() ([]byte, error) { return marshalJSON(a.value()) }
+func (d *Discriminator) MarshalYAML() (any, error)           { return d.object(), nil }
+func (d *Discriminator) MarshalJSON() ([]byte, error)        { return d.object().MarshalJSON() }
@@ -0,0 +1,136 @@
+package openapi
+
+// Swagger is the root object of a Swagger 2.0 document, it shares the schema,
+// info, tag and security types with OpenAPI 3.
+// Swagger 2.0 specification:
+// https://swagger.io/specification/v2/
+type Swagger struct {
+	Swagger             string
+	Info                *Info
+	Host                string
+	BasePath            string
+	Schemes             []string
+	Consumes            []string
+	Produces            []string
+	Paths               map[string]SwaggerPathItem
+	Definitions         map[string]*Schema
+	SecurityDefinitions map[string]*SecurityScheme
+	Security            []SecurityRequirement
+	Tags                []*Tag
+	Extensions          map[string]any
+}
+
+// SwaggerPathItem holds the operations of a path keyed by lowercase HTTP method
+type SwaggerPathItem map[string]*SwaggerOperation
+
+// SwaggerOperation is an API operation on a path
+type SwaggerOperation struct {
+	Tags        []string
+	Summary     string
+	Description string
+	OperationID string
+	Parameters  []*SwaggerParameter
+	Responses   map[string]*SwaggerResponse
+	Deprecated  bool
+	// Security overrides the document security when it isn't nil
+	Security   []SecurityRequirement
+	Extensions map[string]any
+}
+
+// SwaggerParameter is a parameter of an operation, the schema of body
+// parameters is written as is while the keywords of the schema of other
+// parameters are written into the parameter itself
+type SwaggerParameter struct {
+	Name             string
+	In               string
+	Description      string
+	Required         bool
+	Schema           *Schema
+	CollectionFormat string
+	Extensions       map[string]any
+}
+
+// SwaggerResponse is a response of an operation
+type SwaggerResponse struct {
+	Description string
+	Schema      *Schema
+}
+
+func (s *Swagger) object() object {
+	var o object
+	o.add("swagger", s.Swagger)
+	o.add("info", s.Info)
+	o.add("host", s.Host)
+	o.add("basePath", s.BasePath)
+	o.add("schemes", s.Schemes)
+	o.add("consumes", s.Consumes)
+	o.add("produces", s.Produces)
+	o.add("paths", s.Paths)
+	o.add("definitions", s.Definitions)
+	o.add("securityDefinitions", s.SecurityDefinitions)
+	o.add("security", s.Security)
+	o.add("tags", s.Tags)
+	o.addExtensions(s.Extensions)
+	return o
+}
+
+func (p SwaggerPathItem) object() object {
+	var o object
+	for _, method := range operationMethods {
+		o.add(method, p[method])
+	}
+	return o
+}
+
+func (op *SwaggerOperation) object() object {
+	var o object
+	o.add("tags", op.Tags)
+	o.add("summary", op.Summary)
+	o.add("description", op.Description)
+	o.add("operationId", op.OperationID)
+	o.add("parameters", op.Parameters)
+	o.add("responses", op.Responses)
+	o.add("deprecated", op.Deprecated)
+	o.add("security", op.Security)
+	o.addExtensions(op.Extensions)
+	return o
+}
+
+func (p *SwaggerParameter) object() object {
+	var o object
+	o.add("name", p.Name)
+	o.add("in", p.In)
+	o.add("description", p.Description)
+	o.add("required", p.Required)
+	if p.In == "body" {
+		o.add("schema", p.Schema)
+	} else if p.Schema != nil {
+		for _, f := range p.Schema.object() {
+			if !o.has(f.key) {
+				o = append(o, f)
+			}
+		}
+	}
+	o.add("collectionFormat", p.CollectionFormat)
+	o.addExtensions(p.Extensions)
+	return o
+}
+
+func (r *SwaggerResponse) object() object {
+	var o object
+	// description is required even when it's empty
+	o.addAny("description", r.Description)
+	o.add("schema", r.Schema)
+	return o
+}
+
+func (s *Swagger) MarshalYAML() (any, error)              { return s.object(), nil }
+func (s *Swagger) MarshalJSON() ([]byte, error)           { return s.object().MarshalJSON() }
+func (p SwaggerPathItem) MarshalYAML() (any, error)       { return p.object(), nil }
+func (p SwaggerPathItem) MarshalJSON() ([]byte, error)    { return p.object().MarshalJSON() }
+func (op *SwaggerOperation) MarshalYAML() (any, error)    { return op.object(), nil }
+func (op *SwaggerOperation) MarshalJSON() ([]byte, error) { return op.object().MarshalJSON() }
+func (p *SwaggerParameter) MarshalYAML() (any, error)     { return p.object(), nil }
+func (p *SwaggerParameter) MarshalJSON() ([]byte, error)  { return p.object().MarshalJSON() }
+func (r *SwaggerResponse) MarshalYAML() (any, error)      { return r.object(), nil }
+func (r *SwaggerResponse) MarshalJSON() ([]byte, error)   { return r.object().MarshalJSON() }